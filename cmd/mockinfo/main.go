@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"net/http"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/mockinfo"
+)
+
+func main() {
+	logging.Init()
+	logger := logging.GetLogger()
+
+	// Определение флагов командной строки
+	addr := flag.String("addr", ":8081", "Адрес, на котором слушает сервер")
+	fixturesPath := flag.String("fixtures", "cmd/mockinfo/fixtures.json", "Путь к JSON-файлу с фикстурами")
+	latency := flag.Duration("latency", 0, "Задержка перед каждым ответом, например 300ms")
+	errorRate := flag.Float64("error-rate", 0, "Доля запросов, завершающихся ошибкой (0..1)")
+	errorStatus := flag.Int("error-status", http.StatusInternalServerError, "Код ответа при имитации ошибки")
+	malformedRate := flag.Float64("malformed-rate", 0, "Доля ответов с некорректным JSON (0..1)")
+	flag.Parse()
+
+	fixtures, err := mockinfo.LoadFixtures(*fixturesPath)
+	if err != nil {
+		logger.Fatalf("Ошибка загрузки фикстур: %v", err)
+	}
+
+	server := mockinfo.New(fixtures)
+	server.SetFaults(mockinfo.Faults{
+		Latency:       *latency,
+		ErrorRate:     *errorRate,
+		ErrorStatus:   *errorStatus,
+		MalformedRate: *malformedRate,
+	})
+
+	logger.Infof("Мок сервиса информации о песнях слушает %s (фикстур: %d), GET /api/info?group=&song=", *addr, len(fixtures))
+
+	if err := http.ListenAndServe(*addr, server); err != nil {
+		logger.Fatalf("Ошибка запуска сервера: %v", err)
+	}
+}
@@ -2,6 +2,8 @@ package app
 
 import (
 	"context"
+	"expvar"
+	"net"
 	"net/http"
 	"os"
 	"time"
@@ -16,6 +18,7 @@ import (
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository/postgres"
 	router "github.com/pelicanch1k/EffectiveMobileTestTask/internal/router/v1"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/worker"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
 )
 
@@ -60,6 +63,13 @@ func newServer(r http.Handler, logger *logging.Logger) *http.Server {
 
 // registerLifecycle управляет жизненным циклом сервера
 func registerLifecycle(lc fx.Lifecycle, srv *http.Server, logger *logging.Logger) {
+	// Контексты всех запросов наследуются от baseCtx, чтобы при остановке
+	// прервать запросы к БД и внешнему API, не успевшие завершиться
+	baseCtx, cancelRequests := context.WithCancel(context.Background())
+	srv.BaseContext = func(net.Listener) context.Context {
+		return baseCtx
+	}
+
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
 			go func() {
@@ -75,7 +85,9 @@ func registerLifecycle(lc fx.Lifecycle, srv *http.Server, logger *logging.Logger
 			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 			defer cancel()
 
-			if err := srv.Shutdown(ctx); err != nil {
+			err := srv.Shutdown(ctx)
+			cancelRequests()
+			if err != nil {
 				logger.Fatalf("Ошибка при выключении сервера: %s", err.Error())
 			}
 
@@ -85,6 +97,40 @@ func registerLifecycle(lc fx.Lifecycle, srv *http.Server, logger *logging.Logger
 	})
 }
 
+// registerEnrichmentWorker запускает фоновое дополнение песен вместе с приложением.
+// При остановке в лог пишется статистика кэша метаданных
+func registerEnrichmentWorker(lc fx.Lifecycle, w *worker.EnrichmentWorker, cache *external_api.CachedAPI) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			w.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			err := w.Stop(ctx)
+			cache.LogStats()
+			return err
+		},
+	})
+}
+
+// publishCacheStats публикует счетчики кэша метаданных в /debug/vars (expvar)
+func publishCacheStats(cache *external_api.CachedAPI) {
+	expvar.Publish("external_api_cache", expvar.Func(func() interface{} { return cache.Stats() }))
+}
+
+// registerTrashPurgeWorker запускает очистку корзины вместе с приложением
+func registerTrashPurgeWorker(lc fx.Lifecycle, w *worker.TrashPurgeWorker) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			w.Start()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			return w.Stop(ctx)
+		},
+	})
+}
+
 func NewApp() *fx.App {
 	app := fx.New(
 		fx.Invoke(loadEnv),
@@ -92,14 +138,20 @@ func NewApp() *fx.App {
 			newLogger,
 			newDB,
 			repository.NewRepository,
-			fx.Annotate(external_api.NewSongAPI, fx.As(new(service.ExternalAPI))),
+			external_api.NewChainFromEnv,
+			fx.Annotate(external_api.NewCachedAPIFromEnv, fx.As(fx.Self()), fx.As(new(service.ExternalAPI))),
 			service.NewService,
 			handler.NewHandler,
 			router.NewRouter,
 			fx.Annotate(router.NewRouter, fx.As(new(http.Handler))), 
 			newServer,
+			worker.NewEnrichmentWorker,
+			worker.NewTrashPurgeWorker,
 		),
 		fx.Invoke(registerLifecycle),
+		fx.Invoke(registerEnrichmentWorker),
+		fx.Invoke(publishCacheStats),
+		fx.Invoke(registerTrashPurgeWorker),
 	)
 
 	return app
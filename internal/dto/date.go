@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+const (
+	// DateLayout формат даты, в котором API отдает releaseDate
+	DateLayout = "02.01.2006"
+	// ISODateLayout формат даты ISO-8601
+	ISODateLayout = "2006-01-02"
+)
+
+var ErrInvalidDate = errs.Validation("некорректная дата")
+
+// ParseDate разбирает дату в формате ISO-8601 (2006-01-02 или RFC 3339) либо DD.MM.YYYY
+func ParseDate(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	for _, layout := range []string{ISODateLayout, DateLayout, time.RFC3339} {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: %q, ожидается YYYY-MM-DD или DD.MM.YYYY", ErrInvalidDate, value)
+}
+
+// ParseDecade разбирает десятилетие: 1990, 1990s или 90s. Десятилетие 0 не принимается,
+// потому что в фильтрах песен 0 означает отсутствие фильтра
+func ParseDecade(value string) (int, error) {
+	value = strings.TrimSuffix(strings.TrimSpace(value), "s")
+
+	// strconv.Atoi принимает знак, поэтому цифры проверяются отдельно: "+90" не десятилетие
+	if value == "" || strings.TrimLeft(value, "0123456789") != "" {
+		return 0, fmt.Errorf("%w: некорректное десятилетие %q", ErrInvalidDate, value)
+	}
+
+	decade, err := strconv.Atoi(value)
+	if err != nil || len(value) != 2 && decade == 0 {
+		return 0, fmt.Errorf("%w: некорректное десятилетие %q", ErrInvalidDate, value)
+	}
+
+	if len(value) == 2 {
+		// 90s -> 1990, 10s -> 2010
+		if decade >= 30 {
+			decade += 1900
+		} else {
+			decade += 2000
+		}
+	}
+
+	if decade%10 != 0 {
+		return 0, fmt.Errorf("%w: десятилетие %q должно начинаться с года, кратного 10", ErrInvalidDate, value)
+	}
+
+	return decade, nil
+}
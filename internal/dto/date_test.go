@@ -0,0 +1,48 @@
+package dto
+
+import (
+	"testing"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+func TestParseDecade(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "1990", want: 1990},
+		{value: "1990s", want: 1990},
+		{value: " 80s ", want: 1980},
+		{value: "10s", want: 2010},
+		{value: "00s", want: 2000},
+		{value: "0", wantErr: true},
+		{value: "0s", wantErr: true},
+		{value: "000", wantErr: true},
+		{value: "-10", wantErr: true},
+		{value: "+90", wantErr: true},
+		{value: "-90s", wantErr: true},
+		{value: "+1990", wantErr: true},
+		{value: "19 90", wantErr: true},
+		{value: "1985", wantErr: true},
+		{value: "nineties", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := ParseDecade(tt.value)
+			if tt.wantErr {
+				if !errs.Is(err, errs.KindValidation) {
+					t.Fatalf("ParseDecade(%q) = %d, %v; want validation error", tt.value, got, err)
+				}
+				return
+			}
+
+			if err != nil || got != tt.want {
+				t.Fatalf("ParseDecade(%q) = %d, %v; want %d", tt.value, got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package dto
+
+import "github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+
+// @Description Request to add a new group
+type AddGroupRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// @Description Request to rename a group
+type UpdateGroupRequest struct {
+	Id   int    `json:"-"`
+	Name string `json:"name" binding:"required"`
+}
+
+// @Description Request to merge duplicate groups into one
+type MergeGroupsRequest struct {
+	TargetId  int   `json:"targetId" binding:"required"`
+	SourceIds []int `json:"sourceIds" binding:"required,min=1"`
+	DryRun    bool  `json:"dryRun"`
+}
+
+// @Description Result of merging groups
+type MergeGroupsResult struct {
+	TargetId   int          `json:"targetId"`
+	SourceIds  []int        `json:"sourceIds"`
+	DryRun     bool         `json:"dryRun"`
+	MovedSongs int          `json:"movedSongs"`
+	Songs      []model.Song `json:"songs"`
+}
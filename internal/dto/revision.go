@@ -0,0 +1,73 @@
+package dto
+
+import (
+	"context"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/linediff"
+)
+
+// ActorSystem автор изменений, сделанных без запроса клиента, например фоновым дополнением
+const ActorSystem = "system"
+
+// Change автор и причина изменения, которые попадают в ревизию песни
+type Change struct {
+	Actor  string
+	Reason string
+}
+
+type changeKey struct{}
+
+// WithChange сохраняет в контексте автора и причину изменения
+func WithChange(ctx context.Context, change Change) context.Context {
+	return context.WithValue(ctx, changeKey{}, change)
+}
+
+// WithReason заменяет причину изменения, сохраняя автора
+func WithReason(ctx context.Context, reason string) context.Context {
+	change := ChangeFrom(ctx)
+	change.Reason = reason
+
+	return WithChange(ctx, change)
+}
+
+// ChangeFrom возвращает автора и причину изменения из контекста. Без автора
+// изменение считается сделанным системой
+func ChangeFrom(ctx context.Context) Change {
+	change, _ := ctx.Value(changeKey{}).(Change)
+	if change.Actor == "" {
+		change.Actor = ActorSystem
+	}
+
+	return change
+}
+
+// DiffRevisionsQuery номера сравниваемых ревизий
+type DiffRevisionsQuery struct {
+	From int `form:"from" binding:"required,min=1"`
+	To   int `form:"to" binding:"required,min=1"`
+}
+
+// @Description Request to restore a song revision
+type RestoreRevisionRequest struct {
+	SongId   int `json:"-"`
+	Revision int `json:"-"`
+	// Reason причина восстановления, дополняет стандартную
+	Reason string `json:"reason"`
+}
+
+// @Description Field-level difference between two song revisions
+type RevisionDiff struct {
+	SongId  int           `json:"songId"`
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+// @Description Change of a song field between two revisions
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+	// Lines построчная разница, только для text
+	Lines []linediff.Edit `json:"lines,omitempty"`
+}
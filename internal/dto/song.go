@@ -1,13 +1,35 @@
 package dto
 
-// @Description Request to add a new song
+import (
+	"slices"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// @Description Request to add a new song. Release date, lyrics and link left empty are filled in from the song info service
 type AddSongRequest struct {
 	Song        string `json:"song" binding:"required"`
 	Genre       string `json:"genre"`
-	ReleaseDate string `json:"releaseDate" binding:"required"`
-	Text        string `json:"text" binding:"required"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
 	Link        string `json:"link"`
 	Group       string `json:"group" binding:"required"`
+	// Enrich политика слияния с данными внешнего API, передается query-параметром
+	Enrich string `json:"-"`
+}
+
+// EnrichQuery политика слияния с данными внешнего API: never, missing (по умолчанию) или always
+type EnrichQuery struct {
+	Enrich string `form:"enrich" binding:"omitempty,oneof=never missing always"`
+}
+
+// @Description Result of adding a song
+type AddSongResult struct {
+	Id               int    `json:"id"`
+	Enrich           string `json:"enrich"`
+	EnrichmentStatus string `json:"enrichmentStatus"`
 }
 
 // @Description Request to update a song
@@ -19,31 +41,187 @@ type UpdateSongRequest struct {
 	Text        *string `json:"text"`
 	Link        *string `json:"link"`
 	Group       *string `json:"group"`
+	// Reason причина изменения для истории ревизий, заменяет заголовок X-Change-Reason
+	Reason string `json:"reason"`
+	// IfMatch версии из заголовка If-Match
+	IfMatch IfMatch `json:"-"`
 }
 
-// @Description Request to getting songs
-type GetSongsRequest struct {
-	Id          int
-	Genre       string
+// SongFields текущие значения полей песни, которые меняет UpdateSongRequest.
+// Дата выхода в формате ISODateLayout, пустая строка — значение не задано
+type SongFields struct {
 	Song        string
+	Genre       string
 	ReleaseDate string
 	Text        string
 	Link        string
-	GroupId     int 
 	Group       string
-	Limit       int
-	Offset      int
+}
+
+// DropUnchanged убирает из запроса поля, значения которых совпадают с current, и
+// сообщает, осталось ли что менять. Запрос без изменений не создает новую версию песни
+func (r *UpdateSongRequest) DropUnchanged(current SongFields) bool {
+	fields := []struct {
+		value   **string
+		current string
+	}{
+		{&r.Song, current.Song},
+		{&r.Genre, current.Genre},
+		{&r.ReleaseDate, current.ReleaseDate},
+		{&r.Text, current.Text},
+		{&r.Link, current.Link},
+		{&r.Group, current.Group},
+	}
+
+	changed := false
+	for _, field := range fields {
+		if *field.value != nil && **field.value == field.current {
+			*field.value = nil
+		}
+		changed = changed || *field.value != nil
+	}
+
+	return changed
+}
+
+// IfMatch версии песни, с которыми клиент согласен работать (заголовок If-Match).
+// nil означает, что условие не задано, а пустой список не совпадает ни с одной версией
+type IfMatch []int
+
+// Check возвращает PreconditionFailed, если текущая версия песни не входит в список
+func (m IfMatch) Check(songId, version int) error {
+	if m == nil || slices.Contains(m, version) {
+		return nil
+	}
+
+	return errs.PreconditionFailed("песня %d была изменена, текущая версия %d", songId, version)
+}
+
+// @Description Query parameters of the songs listing
+type GetSongsQuery struct {
+	Id             int    `form:"id" binding:"omitempty,min=1"`
+	Genre          string `form:"genre"`
+	Song           string `form:"song"`
+	ReleaseDate    string `form:"releaseDate"`
+	ReleasedAfter  string `form:"releasedAfter"`
+	ReleasedBefore string `form:"releasedBefore"`
+	Year           int    `form:"year" binding:"omitempty,min=1"`
+	Decade         string `form:"decade"`
+	Text           string `form:"text"`
+	Link           string `form:"link"`
+	GroupId        int    `form:"groupId" binding:"omitempty,min=1"`
+	Group          string `form:"group"`
+	Limit          int    `form:"limit" binding:"omitempty,min=0,max=100"`
+	Offset         int    `form:"offset" binding:"omitempty,min=0"`
+	Sort           string `form:"sort"`
+	// EnrichmentStatus отбирает песни по статусу дополнения данными внешнего API
+	EnrichmentStatus string `form:"enrichmentStatus" binding:"omitempty,oneof=pending enriched failed skipped"`
+}
+
+// @Description Request to getting songs
+type GetSongsRequest struct {
+	Id               int
+	Genre            string
+	Song             string
+	ReleaseDate      *time.Time
+	ReleasedAfter    *time.Time
+	ReleasedBefore   *time.Time
+	Year             int
+	Decade           int
+	Text             string
+	Link             string
+	GroupId          int
+	Group            string
+	EnrichmentStatus string
+	Limit            int
+	Offset           int
+	Sort             string
+	SortBy           []SortField
+	Cursor           string
+	After            *SongsCursor
+}
+
+// SortField поле сортировки песен
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// SongsCursor позиция в выборке песен: значения ключей сортировки (SortBy)
+// последней выданной песни и сама сортировка, для которой курсор выдан
+type SongsCursor struct {
+	Sort   string   `json:"s"`
+	Values []string `json:"v"`
+}
+
+// @Description Page of songs for cursor pagination
+type SongsPage struct {
+	Songs      []model.Song `json:"songs"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// GetSongLyricsQuery параметры страницы куплетов. Указатели отличают отсутствующий параметр
+// от явно переданного нуля, который считается ошибкой
+type GetSongLyricsQuery struct {
+	Page  *int `form:"page" binding:"omitempty,min=1"`
+	Limit *int `form:"limit" binding:"omitempty,min=1,max=100"`
+	// Offset устаревший синоним page: раньше номер страницы передавался в offset
+	Offset *int `form:"offset" binding:"omitempty,min=0"`
+	// Lang язык перевода (тег BCP-47)
+	Lang string `form:"lang"`
 }
 
 // @Description Request to get lyrics
 type GetSongLyricsRequest struct {
-	Id     int
-	Limit  int
-	Offset int
+	Id int
+	// Page номер страницы, начиная с 1; 0 означает первую страницу
+	Page int
+	// Limit число куплетов на странице; 0 означает размер по умолчанию
+	Limit int
+	// Lang язык перевода; пустой означает оригинал
+	Lang string
+}
+
+// @Description Page of song lyrics split into verses
+type SongLyrics struct {
+	Verses      []string `json:"verses"`
+	Page        int      `json:"page"`
+	Limit       int      `json:"limit"`
+	TotalVerses int      `json:"total_verses"`
+	HasMore     bool     `json:"has_more"`
+	// Lang язык перевода, пустой для оригинала
+	Lang string `json:"lang,omitempty"`
+	// Original куплеты оригинала той же страницы, по одному на каждый куплет перевода.
+	// Отсутствует, если число куплетов перевода и оригинала различается
+	Original []string `json:"original,omitempty"`
 }
 
 type SongDetails struct {
 	ReleaseDate string `json:"releaseDate"`
 	Text        string `json:"text"`
 	Link        string `json:"link"`
+	// Sources имя поставщика для каждого заполненного поля
+	Sources map[string]string `json:"-"`
+	// Partial данные собраны без части поставщиков, которые были недоступны
+	Partial bool `json:"-"`
+}
+
+const (
+	SearchModeFullText = "fulltext"
+	SearchModeFuzzy    = "fuzzy"
+)
+
+// @Description Request to search songs
+type SearchSongsRequest struct {
+	Query  string      `form:"-"`
+	Mode   string      `form:"mode" binding:"omitempty,oneof=fulltext fuzzy"`
+	Limit  int         `form:"limit" binding:"omitempty,min=1,max=100"`
+	Sort   string      `form:"sort"`
+	SortBy []SortField `form:"-"`
+}
+
+// @Description Request to get type-ahead suggestions
+type SuggestRequest struct {
+	Prefix string `form:"prefix" binding:"required"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
 }
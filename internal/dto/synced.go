@@ -0,0 +1,74 @@
+package dto
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/lrc"
+)
+
+// Форматы синхронизированного текста в ответе
+const (
+	SyncedFormatJSON = "json"
+	SyncedFormatLRC  = "lrc"
+)
+
+// GetSyncedLyricsQuery параметры получения синхронизированного текста
+type GetSyncedLyricsQuery struct {
+	// At позиция воспроизведения: mm:ss.xx или секунды (83.5)
+	At     string `form:"at"`
+	Format string `form:"format" binding:"omitempty,oneof=json lrc"`
+}
+
+// @Description Time-synced lyrics of a song
+type SyncedLyrics struct {
+	SongId int          `json:"songId"`
+	Lines  []SyncedLine `json:"lines"`
+}
+
+// @Description Line of time-synced lyrics
+type SyncedLine struct {
+	// Time время начала в формате LRC mm:ss.xx
+	Time    string `json:"time"`
+	StartMs int64  `json:"startMs"`
+	// Text пустой у паузы, на которой плеер убирает текст с экрана
+	Text string `json:"text"`
+}
+
+// @Description Line of time-synced lyrics playing at the given position
+type SyncedLineAt struct {
+	AtMs int64 `json:"atMs"`
+	// Index номер звучащей строки в lines, -1 до начала первой строки
+	Index int `json:"index"`
+	// Line звучащая строка, null до начала первой строки
+	Line *SyncedLine `json:"line"`
+	// Next следующая строка, null после начала последней
+	Next *SyncedLine `json:"next"`
+}
+
+// maxPlaybackPosition верхняя граница позиции воспроизведения, как у меток LRC
+const maxPlaybackPosition = 10000 * time.Minute
+
+// ParsePlaybackPosition разбирает позицию воспроизведения в формате метки LRC (01:23.45)
+// или в секундах (83.45)
+func ParsePlaybackPosition(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.Contains(value, ":") {
+		at, err := lrc.ParseTime(value)
+		if err != nil {
+			return 0, errs.Validation("некорректная позиция %q, ожидается mm:ss.xx или число секунд", value)
+		}
+		return at, nil
+	}
+
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || math.IsNaN(seconds) || seconds < 0 || seconds*float64(time.Second) >= float64(maxPlaybackPosition) {
+		return 0, errs.Validation("некорректная позиция %q, ожидается mm:ss.xx или число секунд", value)
+	}
+
+	return time.Duration(seconds * float64(time.Second)).Round(time.Millisecond), nil
+}
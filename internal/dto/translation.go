@@ -0,0 +1,26 @@
+package dto
+
+import (
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"golang.org/x/text/language"
+)
+
+// @Description Request to add or replace a lyrics translation
+type SaveTranslationRequest struct {
+	SongId int    `json:"-"`
+	Lang   string `json:"-"`
+	Text   string `json:"text" binding:"required"`
+}
+
+// ParseLanguage проверяет тег языка BCP-47 и приводит его к каноническому виду:
+// en-us -> en-US, iw -> he
+func ParseLanguage(value string) (string, error) {
+	tag, err := language.Parse(strings.TrimSpace(value))
+	if err != nil || tag == language.Und {
+		return "", errs.Validation("некорректный язык %q, ожидается тег BCP-47, например en или pt-BR", value)
+	}
+
+	return tag.String(), nil
+}
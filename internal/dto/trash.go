@@ -0,0 +1,14 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+)
+
+// @Description Song in the trash
+type TrashedSong struct {
+	model.Song
+	// PurgeAt время, после которого фоновая очистка удалит песню окончательно
+	PurgeAt time.Time `json:"purgeAt"`
+}
@@ -0,0 +1,78 @@
+package dto
+
+import "strings"
+
+// @Description Request to add a verse to a song
+type AddVerseRequest struct {
+	SongId int `json:"-"`
+	// Position место нового куплета, начиная с 1; куплеты с этой позиции сдвигаются.
+	// Если не задана, куплет добавляется в конец
+	Position int    `json:"position" binding:"omitempty,min=1"`
+	Type     string `json:"type" binding:"omitempty,oneof=verse chorus bridge intro outro"`
+	Text     string `json:"text" binding:"required"`
+}
+
+// @Description Request to edit a verse
+type UpdateVerseRequest struct {
+	SongId  int     `json:"-"`
+	VerseId int     `json:"-"`
+	Type    *string `json:"type" binding:"omitempty,oneof=verse chorus bridge intro outro"`
+	Text    *string `json:"text"`
+}
+
+// @Description Request to reorder the verses of a song
+type ReorderVersesRequest struct {
+	SongId int `json:"-"`
+	// VerseIds все куплеты песни в новом порядке
+	VerseIds []int `json:"verseIds" binding:"required,min=1"`
+}
+
+// NormalizeLyricsText приводит переводы строк к \n и отбрасывает пробелы в конце строк
+func NormalizeLyricsText(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.ReplaceAll(text, "\r", "\n")
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// SplitVerses делит текст песни на куплеты. Куплеты разделяются одной или несколькими
+// пустыми строками (в том числе из одних пробелов); текст предварительно нормализуется
+// NormalizeLyricsText, пустые строки в начале и конце отбрасываются
+func SplitVerses(text string) []string {
+	verses := []string{}
+	var lines []string
+
+	flush := func() {
+		if len(lines) > 0 {
+			verses = append(verses, strings.Join(lines, "\n"))
+			lines = nil
+		}
+	}
+
+	for _, line := range strings.Split(NormalizeLyricsText(text), "\n") {
+		if line == "" {
+			flush()
+			continue
+		}
+		lines = append(lines, line)
+	}
+	flush()
+
+	return verses
+}
+
+// JoinVerses собирает текст песни из куплетов, разделяя их пустой строкой
+func JoinVerses(verses []string) string {
+	return strings.Join(verses, "\n\n")
+}
+
+// NormalizeText приводит текст песни к виду, в котором он хранится: куплеты из SplitVerses,
+// соединенные JoinVerses
+func NormalizeText(text string) string {
+	return JoinVerses(SplitVerses(text))
+}
@@ -0,0 +1,34 @@
+package dto
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitVerses(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "empty", text: "", want: []string{}},
+		{name: "only blank lines", text: "\n \n\t\n", want: []string{}},
+		{name: "single verse", text: "line one\nline two", want: []string{"line one\nline two"}},
+		{name: "LF", text: "first\n\nsecond", want: []string{"first", "second"}},
+		{name: "CRLF", text: "first\r\nstill first\r\n\r\nsecond", want: []string{"first\nstill first", "second"}},
+		{name: "CR", text: "first\r\rsecond", want: []string{"first", "second"}},
+		{name: "several blank lines", text: "first\n\n\n\nsecond", want: []string{"first", "second"}},
+		{name: "whitespace-only separator", text: "first\n  \t\nsecond", want: []string{"first", "second"}},
+		{name: "leading and trailing blank lines", text: "\n\nfirst\n\nsecond\n\n\n", want: []string{"first", "second"}},
+		{name: "trailing spaces trimmed", text: "first  \nsecond\t", want: []string{"first\nsecond"}},
+		{name: "leading spaces kept", text: "  indented", want: []string{"  indented"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SplitVerses(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("SplitVerses(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
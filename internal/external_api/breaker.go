@@ -0,0 +1,117 @@
+package external_api
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+// ErrCircuitOpen запрос не отправлен, потому что внешний API признан недоступным
+var ErrCircuitOpen = errors.New("circuit breaker открыт")
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case stateOpen:
+		return "open"
+	case stateHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// circuitBreaker после threshold неудач подряд перестает пропускать запросы на cooldown.
+// Затем пропускается один пробный запрос: успех закрывает breaker, неудача снова открывает
+type circuitBreaker struct {
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+	logger    *logging.Logger
+}
+
+func newCircuitBreaker(threshold int, cooldown time.Duration, logger *logging.Logger) *circuitBreaker {
+	return &circuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		logger:    logger,
+	}
+}
+
+// allow сообщает, можно ли сейчас отправить запрос
+func (b *circuitBreaker) allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case stateOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return ErrCircuitOpen
+		}
+		b.setState(stateHalfOpen)
+		b.probing = true
+		return nil
+	case stateHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+		return nil
+	default:
+		return nil
+	}
+}
+
+// success отмечает запрос, на который внешний API ответил
+func (b *circuitBreaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != stateClosed {
+		b.setState(stateClosed)
+	}
+}
+
+// failure отмечает запрос, завершившийся сетевой ошибкой или ошибкой сервера
+func (b *circuitBreaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+
+	if b.state == stateHalfOpen || (b.state == stateClosed && b.failures >= b.threshold) {
+		b.openedAt = b.now()
+		b.setState(stateOpen)
+	}
+}
+
+// release снимает отметку пробного запроса, результат которого не говорит о состоянии внешнего API
+func (b *circuitBreaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+func (b *circuitBreaker) setState(state breakerState) {
+	b.logger.Warnf("Circuit breaker внешнего API: %s -> %s (неудач подряд: %d)", b.state, state, b.failures)
+	b.state = state
+}
@@ -0,0 +1,210 @@
+package external_api
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+// SongDetailsSource источник метаданных, результаты которого кэширует CachedAPI
+type SongDetailsSource interface {
+	GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error)
+}
+
+// CacheConfig настройки кэша метаданных
+type CacheConfig struct {
+	// Size максимальное число записей; 0 отключает кэш
+	Size int
+	// TTL время жизни найденных метаданных
+	TTL time.Duration
+	// NegativeTTL время жизни ответа "песня не найдена"
+	NegativeTTL time.Duration
+}
+
+// CacheConfigFromEnv читает настройки из EXTERNAL_API_CACHE_SIZE, EXTERNAL_API_CACHE_TTL и EXTERNAL_API_CACHE_NEGATIVE_TTL
+func CacheConfigFromEnv() CacheConfig {
+	return CacheConfig{
+		Size:        envInt("EXTERNAL_API_CACHE_SIZE", 1000),
+		TTL:         envDuration("EXTERNAL_API_CACHE_TTL", time.Hour),
+		NegativeTTL: envDuration("EXTERNAL_API_CACHE_NEGATIVE_TTL", 5*time.Minute),
+	}
+}
+
+// CacheStats счетчики обращений к кэшу
+type CacheStats struct {
+	Hits   uint64 `json:"hits"`
+	Misses uint64 `json:"misses"`
+	Size   int    `json:"size"`
+}
+
+type cacheEntry struct {
+	key       string
+	details   *dto.SongDetails
+	err       error
+	expiresAt time.Time
+}
+
+// CachedAPI кэширует метаданные песен в памяти процесса (LRU) по нормализованной паре группа+песня.
+// Кэшируются и отрицательные ответы — песня неизвестна или запрос некорректен — с меньшим TTL.
+// Ошибки недоступности и неполные данные не кэшируются, чтобы после сбоя запросы сразу шли к источнику.
+// Одновременные промахи по одной песне объединяются в один запрос к источнику
+type CachedAPI struct {
+	next   SongDetailsSource
+	config CacheConfig
+	now    func() time.Time
+	logger *logging.Logger
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	loads singleflight.Group
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+func NewCachedAPI(next SongDetailsSource, config CacheConfig) *CachedAPI {
+	return &CachedAPI{
+		next:    next,
+		config:  config,
+		now:     time.Now,
+		logger:  logging.GetLogger(),
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+func NewCachedAPIFromEnv(chain *Chain) *CachedAPI {
+	return NewCachedAPI(chain, CacheConfigFromEnv())
+}
+
+func (c *CachedAPI) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	if c.config.Size <= 0 {
+		return c.next.GetSongDetails(ctx, group, song)
+	}
+
+	key := songKey(group, song)
+
+	if entry, ok := c.get(key); ok {
+		c.hits.Add(1)
+		if entry.err != nil {
+			return nil, entry.err
+		}
+		return copyDetails(entry.details), nil
+	}
+	c.misses.Add(1)
+
+	// Запрос к источнику общий для всех ожидающих, поэтому не отменяется вместе с контекстом
+	// одного из них: каждый перестает ждать по своему контексту, а запрос ограничен таймаутом источника
+	loaded := c.loads.DoChan(key, func() (interface{}, error) {
+		return c.load(context.WithoutCancel(ctx), key, group, song)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case result := <-loaded:
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		return copyDetails(result.Val.(*dto.SongDetails)), nil
+	}
+}
+
+// load запрашивает метаданные у источника и кэширует ответ
+func (c *CachedAPI) load(ctx context.Context, key, group, song string) (*dto.SongDetails, error) {
+	details, err := c.next.GetSongDetails(ctx, group, song)
+	switch {
+	case err == nil && details.Partial:
+		// Неполные данные не кэшируются, как и недоступность: повтор должен дойти до поставщиков
+	case err == nil:
+		c.put(key, copyDetails(details), nil, c.config.TTL)
+	case errs.KindOf(err) == errs.KindNotFound || errs.KindOf(err) == errs.KindValidation:
+		c.put(key, nil, err, c.config.NegativeTTL)
+	}
+
+	return details, err
+}
+
+// Stats возвращает счетчики попаданий и промахов и текущий размер кэша. Безопасен для
+// вызова во время работы; приложение публикует его в /debug/vars
+func (c *CachedAPI) Stats() CacheStats {
+	c.mu.Lock()
+	size := c.order.Len()
+	c.mu.Unlock()
+
+	return CacheStats{Hits: c.hits.Load(), Misses: c.misses.Load(), Size: size}
+}
+
+// LogStats пишет счетчики кэша в лог
+func (c *CachedAPI) LogStats() {
+	stats := c.Stats()
+	c.logger.Infof("Кэш метаданных песен: попаданий %d, промахов %d, записей %d", stats.Hits, stats.Misses, stats.Size)
+}
+
+func (c *CachedAPI) get(key string) (*cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := element.Value.(*cacheEntry)
+	if !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry, true
+}
+
+func (c *CachedAPI) put(key string, details *dto.SongDetails, err error, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &cacheEntry{key: key, details: details, err: err, expiresAt: c.now().Add(ttl)}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+
+	for c.order.Len() > c.config.Size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// copyDetails защищает закэшированное значение от изменений вызывающим кодом
+func copyDetails(details *dto.SongDetails) *dto.SongDetails {
+	if details == nil {
+		return nil
+	}
+
+	copied := *details
+	if details.Sources != nil {
+		copied.Sources = make(map[string]string, len(details.Sources))
+		for field, source := range details.Sources {
+			copied.Sources[field] = source
+		}
+	}
+
+	return &copied
+}
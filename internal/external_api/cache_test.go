@@ -0,0 +1,203 @@
+package external_api
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// countingSource источник метаданных, который считает запросы по песням.
+// Если задан release, запрос ждет его закрытия
+type countingSource struct {
+	mu      sync.Mutex
+	answers map[string]error
+	calls   map[string]int
+	release chan struct{}
+}
+
+func newCountingSource(answers map[string]error) *countingSource {
+	return &countingSource{answers: answers, calls: make(map[string]int)}
+}
+
+func (s *countingSource) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	s.mu.Lock()
+	s.calls[song]++
+	err := s.answers[song]
+	s.mu.Unlock()
+
+	if s.release != nil {
+		<-s.release
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.SongDetails{Text: song, Sources: map[string]string{"text": ProviderInfo}}, nil
+}
+
+func (s *countingSource) callsOf(song string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls[song]
+}
+
+func TestCachedAPI(t *testing.T) {
+	source := newCountingSource(map[string]error{
+		"Unknown":     errs.NotFound("песня не найдена"),
+		"Unavailable": errs.UpstreamUnavailable(errors.New("connection refused"), "внешний API временно недоступен"),
+	})
+	cache := NewCachedAPI(source, CacheConfig{Size: 2, TTL: time.Hour, NegativeTTL: time.Minute})
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.now = func() time.Time { return now }
+
+	steps := []struct {
+		name string
+		// advance сдвиг часов перед запросом
+		advance   time.Duration
+		group     string
+		song      string
+		wantKind  errs.Kind
+		wantCalls int
+		wantStats CacheStats
+	}{
+		{name: "miss", group: "Muse", song: "Hysteria", wantCalls: 1, wantStats: CacheStats{Misses: 1, Size: 1}},
+		{name: "hit with normalized key", group: "  muse ", song: "Hysteria", wantCalls: 1, wantStats: CacheStats{Hits: 1, Misses: 1, Size: 1}},
+		{name: "negative miss", group: "Muse", song: "Unknown", wantKind: errs.KindNotFound, wantCalls: 1, wantStats: CacheStats{Hits: 1, Misses: 2, Size: 2}},
+		{name: "negative hit", advance: 30 * time.Second, group: "Muse", song: "Unknown", wantKind: errs.KindNotFound, wantCalls: 1, wantStats: CacheStats{Hits: 2, Misses: 2, Size: 2}},
+		{name: "negative ttl expired", advance: 30 * time.Second, group: "Muse", song: "Unknown", wantKind: errs.KindNotFound, wantCalls: 2, wantStats: CacheStats{Hits: 2, Misses: 3, Size: 2}},
+		// Hysteria использовалась раньше Unknown, поэтому вытесняется она
+		{name: "lru eviction", group: "Muse", song: "Uprising", wantCalls: 1, wantStats: CacheStats{Hits: 2, Misses: 4, Size: 2}},
+		{name: "evicted entry is loaded again", group: "Muse", song: "Hysteria", wantCalls: 2, wantStats: CacheStats{Hits: 2, Misses: 5, Size: 2}},
+		{name: "recently used entry is kept", group: "Muse", song: "Uprising", wantCalls: 1, wantStats: CacheStats{Hits: 3, Misses: 5, Size: 2}},
+		// Истекшие записи удаляются при обращении, поэтому размер не меняется
+		{name: "ttl expired", advance: time.Hour, group: "Muse", song: "Uprising", wantCalls: 2, wantStats: CacheStats{Hits: 3, Misses: 6, Size: 2}},
+		{name: "unavailability is not cached", group: "Muse", song: "Unavailable", wantKind: errs.KindUpstreamUnavailable, wantCalls: 1, wantStats: CacheStats{Hits: 3, Misses: 7, Size: 2}},
+		{name: "unavailability is requested again", group: "Muse", song: "Unavailable", wantKind: errs.KindUpstreamUnavailable, wantCalls: 2, wantStats: CacheStats{Hits: 3, Misses: 8, Size: 2}},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+
+		details, err := cache.GetSongDetails(context.Background(), step.group, step.song)
+		switch {
+		case step.wantKind == errs.KindInternal && err != nil:
+			t.Fatalf("%s: GetSongDetails: %v", step.name, err)
+		case step.wantKind == errs.KindInternal && details.Text != step.song:
+			t.Fatalf("%s: details = %+v", step.name, details)
+		case step.wantKind != errs.KindInternal && errs.KindOf(err) != step.wantKind:
+			t.Fatalf("%s: err = %v, want %s", step.name, err, step.wantKind)
+		}
+
+		if calls := source.callsOf(step.song); calls != step.wantCalls {
+			t.Fatalf("%s: source calls = %d, want %d", step.name, calls, step.wantCalls)
+		}
+		if stats := cache.Stats(); stats != step.wantStats {
+			t.Fatalf("%s: stats = %+v, want %+v", step.name, stats, step.wantStats)
+		}
+	}
+}
+
+func TestCachedAPIReturnsCopies(t *testing.T) {
+	cache := NewCachedAPI(newCountingSource(nil), CacheConfig{Size: 10, TTL: time.Hour, NegativeTTL: time.Minute})
+
+	details, err := cache.GetSongDetails(context.Background(), "Muse", "Hysteria")
+	if err != nil {
+		t.Fatalf("GetSongDetails: %v", err)
+	}
+	details.Text = "changed"
+	details.Sources["text"] = "changed"
+
+	cached, err := cache.GetSongDetails(context.Background(), "Muse", "Hysteria")
+	if err != nil || cached.Text != "Hysteria" || cached.Sources["text"] != ProviderInfo {
+		t.Fatalf("cached = %+v, %v", cached, err)
+	}
+}
+
+func TestCachedAPIMergesConcurrentMisses(t *testing.T) {
+	source := newCountingSource(nil)
+	source.release = make(chan struct{})
+	cache := NewCachedAPI(source, CacheConfig{Size: 10, TTL: time.Hour, NegativeTTL: time.Minute})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	var failed atomic.Int64
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if details, err := cache.GetSongDetails(context.Background(), "Muse", "Hysteria"); err != nil || details.Text != "Hysteria" {
+				failed.Add(1)
+			}
+		}()
+	}
+
+	// Все запросы дошли до кэша и ждут одного запроса к источнику
+	for cache.Stats().Misses < callers {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+
+	if failed.Load() != 0 {
+		t.Fatalf("%d callers failed", failed.Load())
+	}
+	if calls := source.callsOf("Hysteria"); calls != 1 {
+		t.Fatalf("source calls = %d, want 1", calls)
+	}
+}
+
+func TestCachedAPICanceledCaller(t *testing.T) {
+	source := newCountingSource(nil)
+	source.release = make(chan struct{})
+	cache := NewCachedAPI(source, CacheConfig{Size: 10, TTL: time.Hour, NegativeTTL: time.Minute})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		_, err := cache.GetSongDetails(ctx, "Muse", "Hysteria")
+		done <- err
+	}()
+
+	for source.callsOf("Hysteria") == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+
+	// Запрос к источнику завершается без отмененного вызывающего, и ответ попадает в кэш
+	close(source.release)
+	for cache.Stats().Size == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if _, err := cache.GetSongDetails(context.Background(), "Muse", "Hysteria"); err != nil {
+		t.Fatalf("GetSongDetails: %v", err)
+	}
+	if calls := source.callsOf("Hysteria"); calls != 1 {
+		t.Fatalf("source calls = %d, want 1", calls)
+	}
+}
+
+func TestCachedAPIDoesNotCachePartialDetails(t *testing.T) {
+	provider := &stubProvider{name: ProviderStatic, details: &dto.SongDetails{Link: "https://example.com", Partial: true}}
+	cache := NewCachedAPI(provider, CacheConfig{Size: 10, TTL: time.Hour, NegativeTTL: time.Minute})
+
+	for i := 0; i < 2; i++ {
+		if _, err := cache.GetSongDetails(context.Background(), "Muse", "Hysteria"); err != nil {
+			t.Fatalf("GetSongDetails: %v", err)
+		}
+	}
+
+	if provider.calls != 2 {
+		t.Fatalf("calls = %d, want 2: partial details must not be cached", provider.calls)
+	}
+}
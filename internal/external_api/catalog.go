@@ -0,0 +1,137 @@
+package external_api
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// catalogEntry запись локального каталога; пустые поля означают, что данных нет
+type catalogEntry struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// CatalogProvider отдает метаданные из локального файла JSON (массив записей)
+// или CSV (строка заголовка с колонками group, song, releaseDate, text, link).
+// Файл читается один раз при запуске
+type CatalogProvider struct {
+	entries map[string]catalogEntry
+}
+
+func NewCatalogProvider(path string) (*CatalogProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("не задан путь к каталогу метаданных ENRICHMENT_CATALOG_PATH")
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка открытия каталога метаданных: %w", err)
+	}
+	defer file.Close()
+
+	var entries []catalogEntry
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.NewDecoder(file).Decode(&entries)
+	case ".csv":
+		entries, err = readCatalogCSV(file)
+	default:
+		return nil, fmt.Errorf("неподдерживаемый формат каталога метаданных %q, ожидается .json или .csv", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога метаданных %s: %w", path, err)
+	}
+
+	catalog := &CatalogProvider{entries: make(map[string]catalogEntry, len(entries))}
+	for _, entry := range entries {
+		catalog.entries[songKey(entry.Group, entry.Song)] = entry
+	}
+
+	return catalog, nil
+}
+
+func (p *CatalogProvider) Name() string {
+	return ProviderCatalog
+}
+
+func (p *CatalogProvider) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	entry, ok := p.entries[songKey(group, song)]
+	if !ok {
+		return nil, errs.NotFound("песни %q группы %q нет в каталоге", song, group)
+	}
+
+	return &dto.SongDetails{
+		ReleaseDate: entry.ReleaseDate,
+		Text:        entry.Text,
+		Link:        entry.Link,
+	}, nil
+}
+
+// songKey ключ поиска песни без учета регистра и лишних пробелов
+func songKey(group, song string) string {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.Join(strings.Fields(value), " "))
+	}
+	return normalize(group) + "\x00" + normalize(song)
+}
+
+func readCatalogCSV(r io.Reader) ([]catalogEntry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	for _, required := range []string{"group", "song"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("в заголовке нет колонки %q", required)
+		}
+	}
+
+	value := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	var entries []catalogEntry
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, catalogEntry{
+			Group:       value(record, "group"),
+			Song:        value(record, "song"),
+			ReleaseDate: value(record, "releaseDate"),
+			Text:        value(record, "text"),
+			Link:        value(record, "link"),
+		})
+	}
+
+	return entries, nil
+}
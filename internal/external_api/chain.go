@@ -0,0 +1,130 @@
+package external_api
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+// Имена поставщиков метаданных для ENRICHMENT_PROVIDERS
+const (
+	ProviderInfo    = "info"
+	ProviderCatalog = "catalog"
+	ProviderStatic  = "static"
+)
+
+// Provider источник метаданных песни. Может заполнить только часть полей;
+// если песня ему неизвестна, возвращает ошибку вида errs.KindNotFound
+type Provider interface {
+	Name() string
+	GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error)
+}
+
+// Chain опрашивает поставщиков по порядку: каждое поле берется у первого поставщика,
+// у которого оно есть, а имя поставщика записывается в SongDetails.Sources
+type Chain struct {
+	providers []Provider
+	logger    *logging.Logger
+}
+
+func NewChain(providers ...Provider) *Chain {
+	return &Chain{providers: providers, logger: logging.GetLogger()}
+}
+
+// NewChainFromEnv собирает цепочку из ENRICHMENT_PROVIDERS — имен поставщиков через запятую
+// (info, catalog, static). По умолчанию используется только сервис информации о песнях
+func NewChainFromEnv() (*Chain, error) {
+	names := os.Getenv("ENRICHMENT_PROVIDERS")
+	if strings.TrimSpace(names) == "" {
+		names = ProviderInfo
+	}
+
+	var providers []Provider
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case ProviderInfo:
+			providers = append(providers, NewSongAPI())
+		case ProviderCatalog:
+			catalog, err := NewCatalogProvider(os.Getenv("ENRICHMENT_CATALOG_PATH"))
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, catalog)
+		case ProviderStatic:
+			providers = append(providers, NewStaticProviderFromEnv())
+		case "":
+		default:
+			return nil, fmt.Errorf("неизвестный поставщик метаданных в ENRICHMENT_PROVIDERS: %q", name)
+		}
+	}
+
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("в ENRICHMENT_PROVIDERS не задано ни одного поставщика")
+	}
+
+	return NewChain(providers...), nil
+}
+
+// GetSongDetails собирает метаданные песни у поставщиков цепочки. Поставщики, не знающие
+// песню или недоступные, пропускаются; если недоступен был хотя бы один, результат помечается
+// Partial, чтобы дополнение повторилось, когда поставщик восстановится. Если ни один не дал данных,
+// возвращается ошибка недоступности (дополнение стоит повторить) либо, если песню не знает никто, ошибка валидации
+func (c *Chain) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	result := &dto.SongDetails{Sources: map[string]string{}}
+	found := false
+
+	var unavailable error
+	for _, provider := range c.providers {
+		details, err := provider.GetSongDetails(ctx, group, song)
+		switch {
+		case err == nil:
+		case ctx.Err() != nil:
+			return nil, ctx.Err()
+		case errs.KindOf(err) == errs.KindNotFound:
+			continue
+		case errs.KindOf(err) == errs.KindValidation:
+			return nil, err
+		default:
+			c.logger.Warnf("Поставщик метаданных %s недоступен: %v", provider.Name(), err)
+			if unavailable == nil {
+				unavailable = err
+			}
+			continue
+		}
+
+		found = true
+		fill(result, model.FieldReleaseDate, &result.ReleaseDate, details.ReleaseDate, provider.Name())
+		fill(result, model.FieldText, &result.Text, details.Text, provider.Name())
+		fill(result, model.FieldLink, &result.Link, details.Link, provider.Name())
+
+		if result.ReleaseDate != "" && result.Text != "" && result.Link != "" {
+			break
+		}
+	}
+
+	if !found {
+		if unavailable != nil {
+			return nil, errs.Wrap(errs.KindUpstreamUnavailable, unavailable, "поставщики метаданных недоступны")
+		}
+		return nil, errs.Validation("ни один поставщик метаданных не нашел песню %q группы %q", song, group)
+	}
+
+	result.Partial = unavailable != nil
+	return result, nil
+}
+
+// fill заполняет пустое поле значением поставщика
+func fill(result *dto.SongDetails, field string, target *string, value, provider string) {
+	if *target != "" || value == "" {
+		return
+	}
+
+	*target = value
+	result.Sources[field] = provider
+}
@@ -0,0 +1,161 @@
+package external_api
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// stubProvider поставщик с заранее заданным ответом
+type stubProvider struct {
+	name    string
+	details *dto.SongDetails
+	err     error
+	calls   int
+}
+
+func (p *stubProvider) Name() string {
+	return p.name
+}
+
+func (p *stubProvider) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	p.calls++
+	return copyDetails(p.details), p.err
+}
+
+func TestChainGetSongDetails(t *testing.T) {
+	unavailable := errs.UpstreamUnavailable(errors.New("connection refused"), "внешний API временно недоступен")
+	notFound := errs.NotFound("песня не найдена")
+	static := &dto.SongDetails{ReleaseDate: "01.01.1970", Text: "Текст не найден", Link: "https://example.com/search"}
+
+	tests := []struct {
+		name      string
+		providers []*stubProvider
+		// wantKind вид ошибки; без ошибки wantErr false
+		wantErr     bool
+		wantKind    errs.Kind
+		wantDetails dto.SongDetails
+		wantCalls   []int
+	}{
+		{
+			name: "fields are taken in provider order",
+			providers: []*stubProvider{
+				{name: ProviderInfo, details: &dto.SongDetails{Text: "Ooh baby"}},
+				{name: ProviderCatalog, details: &dto.SongDetails{Text: "Другой текст", Link: "https://example.com"}},
+				{name: ProviderStatic, details: static},
+			},
+			wantDetails: dto.SongDetails{
+				ReleaseDate: static.ReleaseDate, Text: "Ooh baby", Link: "https://example.com",
+				Sources: map[string]string{model.FieldReleaseDate: ProviderStatic, model.FieldText: ProviderInfo, model.FieldLink: ProviderCatalog},
+			},
+			wantCalls: []int{1, 1, 1},
+		},
+		{
+			name: "complete details stop the chain",
+			providers: []*stubProvider{
+				{name: ProviderCatalog, details: &dto.SongDetails{ReleaseDate: "16.07.2006", Text: "Ooh baby", Link: "https://example.com"}},
+				{name: ProviderInfo, err: unavailable},
+			},
+			wantDetails: dto.SongDetails{
+				ReleaseDate: "16.07.2006", Text: "Ooh baby", Link: "https://example.com",
+				Sources: map[string]string{model.FieldReleaseDate: ProviderCatalog, model.FieldText: ProviderCatalog, model.FieldLink: ProviderCatalog},
+			},
+			wantCalls: []int{1, 0},
+		},
+		{
+			name: "not found passes to the next provider",
+			providers: []*stubProvider{
+				{name: ProviderInfo, err: notFound},
+				{name: ProviderStatic, details: static},
+			},
+			wantDetails: dto.SongDetails{
+				ReleaseDate: static.ReleaseDate, Text: static.Text, Link: static.Link,
+				Sources: map[string]string{model.FieldReleaseDate: ProviderStatic, model.FieldText: ProviderStatic, model.FieldLink: ProviderStatic},
+			},
+			wantCalls: []int{1, 1},
+		},
+		{
+			name: "fallback for unavailable provider is partial",
+			providers: []*stubProvider{
+				{name: ProviderInfo, err: unavailable},
+				{name: ProviderStatic, details: static},
+			},
+			wantDetails: dto.SongDetails{
+				ReleaseDate: static.ReleaseDate, Text: static.Text, Link: static.Link,
+				Sources: map[string]string{model.FieldReleaseDate: ProviderStatic, model.FieldText: ProviderStatic, model.FieldLink: ProviderStatic},
+				Partial: true,
+			},
+			wantCalls: []int{1, 1},
+		},
+		{
+			name: "all providers unavailable",
+			providers: []*stubProvider{
+				{name: ProviderInfo, err: unavailable},
+				{name: ProviderCatalog, err: unavailable},
+			},
+			wantErr:   true,
+			wantKind:  errs.KindUpstreamUnavailable,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name: "unavailable and not found",
+			providers: []*stubProvider{
+				{name: ProviderInfo, err: unavailable},
+				{name: ProviderCatalog, err: notFound},
+			},
+			wantErr:   true,
+			wantKind:  errs.KindUpstreamUnavailable,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name: "nobody knows the song",
+			providers: []*stubProvider{
+				{name: ProviderInfo, err: notFound},
+				{name: ProviderCatalog, err: notFound},
+			},
+			wantErr:   true,
+			wantKind:  errs.KindValidation,
+			wantCalls: []int{1, 1},
+		},
+		{
+			name: "invalid request stops the chain",
+			providers: []*stubProvider{
+				{name: ProviderInfo, err: errs.Validation("некорректный запрос")},
+				{name: ProviderStatic, details: static},
+			},
+			wantErr:   true,
+			wantKind:  errs.KindValidation,
+			wantCalls: []int{1, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			providers := make([]Provider, 0, len(tt.providers))
+			for _, provider := range tt.providers {
+				providers = append(providers, provider)
+			}
+
+			details, err := NewChain(providers...).GetSongDetails(context.Background(), "Muse", "Hysteria")
+			switch {
+			case !tt.wantErr && err != nil:
+				t.Fatalf("GetSongDetails: %v", err)
+			case !tt.wantErr && !reflect.DeepEqual(*details, tt.wantDetails):
+				t.Fatalf("details = %+v, want %+v", *details, tt.wantDetails)
+			case tt.wantErr && (err == nil || errs.KindOf(err) != tt.wantKind):
+				t.Fatalf("err = %v (%s), want %s", err, errs.KindOf(err), tt.wantKind)
+			}
+
+			for i, provider := range tt.providers {
+				if provider.calls != tt.wantCalls[i] {
+					t.Errorf("provider %d (%s): calls = %d, want %d", i, provider.name, provider.calls, tt.wantCalls[i])
+				}
+			}
+		})
+	}
+}
@@ -1,56 +1,255 @@
 package external_api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
+	"time"
 
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
 )
 
-type SongAPI struct {
-	baseURL string
+// Config настройки клиента внешнего API
+type Config struct {
+	BaseURL string
+	// Timeout ограничение одной попытки запроса
+	Timeout time.Duration
+	// MaxRetries число повторов после первой неудачной попытки
+	MaxRetries int
+	// RetryBaseDelay и RetryMaxDelay задают экспоненциальную задержку между повторами
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// BreakerThreshold число неудач подряд, после которого запросы перестают отправляться
+	BreakerThreshold int
+	// BreakerCooldown время до пробного запроса после открытия breaker
+	BreakerCooldown time.Duration
 }
 
-func NewSongAPI() *SongAPI {
+// ConfigFromEnv читает настройки из переменных окружения, для незаданных используются значения по умолчанию
+func ConfigFromEnv() Config {
 	baseURL := os.Getenv("URL_ADD_SONG")
 	if baseURL == "" {
-		baseURL = "http://localhost:8080/api"
+		baseURL = "http://localhost:8081/api"
+	}
+
+	return Config{
+		BaseURL:          baseURL,
+		Timeout:          envDuration("EXTERNAL_API_TIMEOUT", 5*time.Second),
+		MaxRetries:       envInt("EXTERNAL_API_MAX_RETRIES", 3),
+		RetryBaseDelay:   envDuration("EXTERNAL_API_RETRY_BASE_DELAY", 200*time.Millisecond),
+		RetryMaxDelay:    envDuration("EXTERNAL_API_RETRY_MAX_DELAY", 2*time.Second),
+		BreakerThreshold: envPositiveInt("EXTERNAL_API_BREAKER_THRESHOLD", 5),
+		BreakerCooldown:  envDuration("EXTERNAL_API_BREAKER_COOLDOWN", 30*time.Second),
 	}
+}
+
+type SongAPI struct {
+	config  Config
+	client  *http.Client
+	breaker *circuitBreaker
+	logger  *logging.Logger
+}
+
+func NewSongAPI() *SongAPI {
+	return NewSongAPIWithConfig(ConfigFromEnv())
+}
+
+func NewSongAPIWithConfig(config Config) *SongAPI {
+	logger := logging.GetLogger()
 
 	return &SongAPI{
-		baseURL: baseURL,
+		config:  config,
+		client:  &http.Client{Timeout: config.Timeout},
+		breaker: newCircuitBreaker(config.BreakerThreshold, config.BreakerCooldown, logger),
+		logger:  logger,
 	}
 }
 
-// GetSongDetails получает информацию о песне из внешнего API
-func (api *SongAPI) GetSongDetails(group, song string) (*dto.SongDetails, error) {
+func (api *SongAPI) Name() string {
+	return ProviderInfo
+}
+
+// retryableError неудачная попытка, которую имеет смысл повторить
+type retryableError struct {
+	err error
+	// retryAfter задержка из заголовка Retry-After, если сервер ее указал
+	retryAfter time.Duration
+}
+
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// GetSongDetails получает информацию о песне из внешнего API.
+// Сетевые ошибки и ответы 5xx и 429 повторяются с экспоненциальной задержкой и джиттером
+// и считаются неудачами breaker. Retry-After соблюдается, если он не длиннее RetryMaxDelay,
+// иначе повторы прекращаются. Прочие ответы 4xx не повторяются и не влияют на breaker
+func (api *SongAPI) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
 	if group == "" || song == "" {
-		return nil, errors.New("отсутствуют обязательные поля group и song")
+		return nil, errs.Validation("отсутствуют обязательные поля group и song")
 	}
 
 	requestURL := fmt.Sprintf("%s/info?group=%s&song=%s",
-		api.baseURL,
+		api.config.BaseURL,
 		url.QueryEscape(group),
 		url.QueryEscape(song))
 
-	resp, err := http.Get(requestURL)
+	var lastErr error
+	var retryAfter time.Duration
+	for attempt := 0; attempt <= api.config.MaxRetries; attempt++ {
+		if err := api.breaker.allow(); err != nil {
+			return nil, errs.UpstreamUnavailable(err, "внешний API временно недоступен")
+		}
+
+		if attempt > 0 {
+			delay := max(api.backoff(attempt), retryAfter)
+			api.logger.Warnf("Повтор запроса к внешнему API через %s (попытка %d из %d): %v",
+				delay, attempt+1, api.config.MaxRetries+1, lastErr)
+
+			select {
+			case <-ctx.Done():
+				api.breaker.release()
+				return nil, ctx.Err()
+			case <-time.After(delay):
+			}
+		}
+
+		details, err := api.fetch(ctx, requestURL, group, song)
+
+		var retryable *retryableError
+		switch {
+		case err == nil:
+			api.breaker.success()
+			return details, nil
+		case ctx.Err() != nil:
+			api.breaker.release()
+			return nil, ctx.Err()
+		case errors.As(err, &retryable):
+			api.breaker.failure()
+			lastErr, retryAfter = retryable.err, retryable.retryAfter
+			if retryAfter > api.config.RetryMaxDelay {
+				return nil, errs.UpstreamUnavailable(lastErr, "внешний API просит повторить запрос через %s", retryAfter)
+			}
+		case errs.KindOf(err) == errs.KindUpstreamUnavailable:
+			api.breaker.failure()
+			return nil, err
+		case errs.KindOf(err) == errs.KindNotFound:
+			// Внешний API ответил, пусть и отказом: он доступен
+			api.breaker.success()
+			return nil, err
+		default:
+			// Неожиданный ответ 4xx говорит об ошибке в запросе, а не о доступности внешнего API
+			api.breaker.release()
+			return nil, err
+		}
+	}
+
+	return nil, errs.UpstreamUnavailable(lastErr, "внешний API недоступен после %d попыток", api.config.MaxRetries+1)
+}
+
+// fetch выполняет одну попытку запроса
+func (api *SongAPI) fetch(ctx context.Context, requestURL, group, song string) (*dto.SongDetails, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := api.client.Do(req)
 	if err != nil {
-		return nil, errors.New("ошибка при выполнении запроса к внешнему API")
+		return nil, &retryableError{err: fmt.Errorf("ошибка при выполнении запроса к внешнему API: %w", err)}
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
+	switch {
+	case resp.StatusCode == http.StatusOK:
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, errs.NotFound("внешний API не нашел песню %q группы %q", song, group)
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return nil, &retryableError{
+			err:        fmt.Errorf("внешний API ограничил частоту запросов: %d", resp.StatusCode),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return nil, &retryableError{
+			err:        fmt.Errorf("внешний API вернул код ошибки: %d", resp.StatusCode),
+			retryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	default:
 		return nil, fmt.Errorf("внешний API вернул код ошибки: %d", resp.StatusCode)
 	}
 
 	var songDetails dto.SongDetails
 	if err := json.NewDecoder(resp.Body).Decode(&songDetails); err != nil {
-		return nil, errors.New("ошибка декодирования ответа от внешнего API")
+		return nil, errs.UpstreamUnavailable(err, "ошибка декодирования ответа от внешнего API")
 	}
 
 	return &songDetails, nil
 }
+
+// backoff возвращает задержку перед повтором attempt: случайное значение
+// из [d/2, d], где d = RetryBaseDelay * 2^(attempt-1), но не больше RetryMaxDelay
+func (api *SongAPI) backoff(attempt int) time.Duration {
+	delay := api.config.RetryBaseDelay << (attempt - 1)
+	if delay <= 0 || delay > api.config.RetryMaxDelay {
+		delay = api.config.RetryMaxDelay
+	}
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+
+	return half + rand.N(half+1)
+}
+
+// parseRetryAfter разбирает заголовок Retry-After: число секунд или дату HTTP.
+// Без заголовка или с некорректным значением возвращается 0
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return max(time.Duration(seconds)*time.Second, 0)
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0)
+	}
+
+	return 0
+}
+
+func envDuration(name string, fallback time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(name)); err == nil && value > 0 {
+		return value
+	}
+	return fallback
+}
+
+func envInt(name string, fallback int) int {
+	if value, err := strconv.Atoi(os.Getenv(name)); err == nil && value >= 0 {
+		return value
+	}
+	return fallback
+}
+
+// envPositiveInt как envInt, но 0 тоже заменяется значением по умолчанию
+func envPositiveInt(name string, fallback int) int {
+	if value := envInt(name, fallback); value > 0 {
+		return value
+	}
+	return fallback
+}
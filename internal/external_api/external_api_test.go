@@ -0,0 +1,403 @@
+package external_api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/mockinfo"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/mockinfo/mockinfotest"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitNop()
+
+	os.Exit(m.Run())
+}
+
+// response ответ тестового сервера на один запрос
+type response struct {
+	status     int
+	retryAfter string
+	body       string
+}
+
+// newTestServer отвечает на запросы по порядку ответами responses, последний повторяется.
+// Возвращает адрес сервера и счетчик запросов
+func newTestServer(t *testing.T, responses ...response) (string, *atomic.Int64) {
+	t.Helper()
+
+	var requests atomic.Int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(requests.Add(1))
+		resp := responses[min(n, len(responses))-1]
+
+		if resp.retryAfter != "" {
+			w.Header().Set("Retry-After", resp.retryAfter)
+		}
+		if resp.body == "" && resp.status == http.StatusOK {
+			resp.body = `{"releaseDate":"16.07.2006","text":"Ooh baby","link":"https://example.com"}`
+		}
+
+		w.WriteHeader(resp.status)
+		w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+
+	return server.URL, &requests
+}
+
+func newTestAPI(baseURL string, maxRetries, breakerThreshold int) *SongAPI {
+	return NewSongAPIWithConfig(Config{
+		BaseURL:          baseURL,
+		Timeout:          time.Second,
+		MaxRetries:       maxRetries,
+		RetryBaseDelay:   time.Millisecond,
+		RetryMaxDelay:    5 * time.Millisecond,
+		BreakerThreshold: breakerThreshold,
+		BreakerCooldown:  time.Minute,
+	})
+}
+
+func TestGetSongDetails(t *testing.T) {
+	tests := []struct {
+		name      string
+		responses []response
+		// priorFailures неудачи breaker подряд до запроса
+		priorFailures int
+		// wantKind вид ошибки, errs.KindInternal — ошибка без вида; без ошибки wantErr false
+		wantErr      bool
+		wantKind     errs.Kind
+		wantRequests int64
+		wantFailures int
+	}{
+		{
+			name:         "success",
+			responses:    []response{{status: http.StatusOK}},
+			wantRequests: 1,
+		},
+		{
+			name:          "server errors then success",
+			responses:     []response{{status: http.StatusInternalServerError}, {status: http.StatusBadGateway}, {status: http.StatusOK}},
+			priorFailures: 1,
+			wantRequests:  3,
+		},
+		{
+			name:         "server errors exhaust retries",
+			responses:    []response{{status: http.StatusServiceUnavailable}},
+			wantErr:      true,
+			wantKind:     errs.KindUpstreamUnavailable,
+			wantRequests: 3,
+			wantFailures: 3,
+		},
+		{
+			name:         "too many requests then success",
+			responses:    []response{{status: http.StatusTooManyRequests, retryAfter: "0"}, {status: http.StatusOK}},
+			wantRequests: 2,
+		},
+		{
+			name:         "too many requests exhaust retries",
+			responses:    []response{{status: http.StatusTooManyRequests}},
+			wantErr:      true,
+			wantKind:     errs.KindUpstreamUnavailable,
+			wantRequests: 3,
+			wantFailures: 3,
+		},
+		{
+			name:         "retry-after longer than max delay",
+			responses:    []response{{status: http.StatusTooManyRequests, retryAfter: "3600"}, {status: http.StatusOK}},
+			wantErr:      true,
+			wantKind:     errs.KindUpstreamUnavailable,
+			wantRequests: 1,
+			wantFailures: 1,
+		},
+		{
+			name:          "not found is an answer",
+			responses:     []response{{status: http.StatusNotFound}},
+			priorFailures: 2,
+			wantErr:       true,
+			wantKind:      errs.KindNotFound,
+			wantRequests:  1,
+		},
+		{
+			name:          "bad request is not retried and keeps breaker state",
+			responses:     []response{{status: http.StatusBadRequest}, {status: http.StatusOK}},
+			priorFailures: 2,
+			wantErr:       true,
+			wantKind:      errs.KindInternal,
+			wantRequests:  1,
+			wantFailures:  2,
+		},
+		{
+			name:         "malformed json",
+			responses:    []response{{status: http.StatusOK, body: `{"releaseDate": "16.07`}},
+			wantErr:      true,
+			wantKind:     errs.KindUpstreamUnavailable,
+			wantRequests: 1,
+			wantFailures: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			baseURL, requests := newTestServer(t, tt.responses...)
+			api := newTestAPI(baseURL, 2, 10)
+			api.breaker.failures = tt.priorFailures
+
+			details, err := api.GetSongDetails(context.Background(), "Muse", "Supermassive Black Hole")
+			switch {
+			case !tt.wantErr && err != nil:
+				t.Fatalf("GetSongDetails: %v", err)
+			case !tt.wantErr && details.Text != "Ooh baby":
+				t.Fatalf("details = %+v", details)
+			case tt.wantErr && (err == nil || errs.KindOf(err) != tt.wantKind):
+				t.Fatalf("err = %v (%s), want %s", err, errs.KindOf(err), tt.wantKind)
+			}
+
+			if got := requests.Load(); got != tt.wantRequests {
+				t.Errorf("requests = %d, want %d", got, tt.wantRequests)
+			}
+			if api.breaker.failures != tt.wantFailures {
+				t.Errorf("breaker failures = %d, want %d", api.breaker.failures, tt.wantFailures)
+			}
+		})
+	}
+}
+
+func TestGetSongDetailsOpensBreaker(t *testing.T) {
+	baseURL, requests := newTestServer(t, response{status: http.StatusInternalServerError})
+	api := newTestAPI(baseURL, 0, 2)
+
+	for i := 0; i < 2; i++ {
+		if _, err := api.GetSongDetails(context.Background(), "Muse", "Hysteria"); !errs.Is(err, errs.KindUpstreamUnavailable) {
+			t.Fatalf("request %d: err = %v, want upstream unavailable", i+1, err)
+		}
+	}
+
+	_, err := api.GetSongDetails(context.Background(), "Muse", "Hysteria")
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want ErrCircuitOpen", err)
+	}
+	if got := requests.Load(); got != 2 {
+		t.Fatalf("requests = %d, want 2: open breaker must not send requests", got)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := newCircuitBreaker(2, time.Minute, logging.GetLogger())
+	breaker.now = func() time.Time { return now }
+
+	steps := []struct {
+		name string
+		// advance сдвиг часов перед действием
+		advance time.Duration
+		action  func()
+		// wantAllow результат allow после действия
+		wantAllow error
+		wantState breakerState
+	}{
+		{name: "closed", action: func() {}, wantState: stateClosed},
+		{name: "first failure", action: breaker.failure, wantState: stateClosed},
+		{name: "success resets failures", action: breaker.success, wantState: stateClosed},
+		{name: "failure after reset", action: breaker.failure, wantState: stateClosed},
+		{name: "threshold reached", action: breaker.failure, wantAllow: ErrCircuitOpen, wantState: stateOpen},
+		{name: "cooldown not expired", advance: 59 * time.Second, action: func() {}, wantAllow: ErrCircuitOpen, wantState: stateOpen},
+		// allow после cooldown пропускает пробный запрос, следующий allow уже отказывает
+		{name: "probe in flight", advance: time.Second, action: func() { breaker.allow() }, wantAllow: ErrCircuitOpen, wantState: stateHalfOpen},
+		{name: "released probe", action: breaker.release, wantAllow: nil, wantState: stateHalfOpen},
+		{name: "failed probe reopens", action: breaker.failure, wantAllow: ErrCircuitOpen, wantState: stateOpen},
+		{name: "second probe", advance: time.Minute, action: func() { breaker.allow() }, wantAllow: ErrCircuitOpen, wantState: stateHalfOpen},
+		{name: "successful probe closes", action: breaker.success, wantAllow: nil, wantState: stateClosed},
+	}
+
+	for _, step := range steps {
+		now = now.Add(step.advance)
+		step.action()
+
+		if breaker.state != step.wantState {
+			t.Fatalf("%s: state = %s, want %s", step.name, breaker.state, step.wantState)
+		}
+		if err := breaker.allow(); !errors.Is(err, step.wantAllow) {
+			t.Fatalf("%s: allow() = %v, want %v", step.name, err, step.wantAllow)
+		}
+		// Проверочный allow не должен оставлять пробный запрос
+		if step.wantAllow == nil {
+			breaker.release()
+		}
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	api := newTestAPI("", 5, 1)
+	api.config.RetryBaseDelay = 100 * time.Millisecond
+	api.config.RetryMaxDelay = time.Second
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 100 * time.Millisecond},
+		{attempt: 2, want: 200 * time.Millisecond},
+		{attempt: 3, want: 400 * time.Millisecond},
+		{attempt: 4, want: 800 * time.Millisecond},
+		{attempt: 5, want: time.Second},
+		{attempt: 70, want: time.Second},
+	}
+
+	for _, tt := range tests {
+		lowest, highest := tt.want, time.Duration(0)
+		for i := 0; i < 200; i++ {
+			delay := api.backoff(tt.attempt)
+			if delay < tt.want/2 || delay > tt.want {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s]", tt.attempt, delay, tt.want/2, tt.want)
+			}
+			lowest, highest = min(lowest, delay), max(highest, delay)
+		}
+
+		if lowest == highest {
+			t.Fatalf("backoff(%d) always %s, want jitter", tt.attempt, lowest)
+		}
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 0},
+		{value: "5", want: 5 * time.Second},
+		{value: "-5", want: 0},
+		{value: now.Add(90 * time.Second).Format(http.TimeFormat), want: 90 * time.Second},
+		{value: now.Add(-time.Minute).Format(http.TimeFormat), want: 0},
+		{value: "soon", want: 0},
+	}
+
+	for _, tt := range tests {
+		if got := parseRetryAfter(tt.value, now); got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestConfigFromEnvBreakerThreshold(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int
+	}{
+		{value: "", want: 5},
+		{value: "3", want: 3},
+		{value: "0", want: 5},
+		{value: "-1", want: 5},
+		{value: "many", want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("EXTERNAL_API_BREAKER_THRESHOLD", tt.value)
+
+		if got := ConfigFromEnv().BreakerThreshold; got != tt.want {
+			t.Errorf("EXTERNAL_API_BREAKER_THRESHOLD=%q: threshold = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestGetSongDetailsMockInfo проверяет клиента против мока сервиса из pkg/mockinfo
+func TestGetSongDetailsMockInfo(t *testing.T) {
+	fixture := mockinfo.Fixture{
+		Group:       "Muse",
+		Song:        "Supermassive Black Hole",
+		ReleaseDate: "16.07.2006",
+		Text:        "Ooh baby, don't you know I suffer?",
+		Link:        "https://www.youtube.com/watch?v=Xsp3_a-PMTw",
+	}
+
+	tests := []struct {
+		name   string
+		group  string
+		song   string
+		faults mockinfo.Faults
+		// wantKind вид ошибки, без ошибки wantErr false
+		wantErr      bool
+		wantKind     errs.Kind
+		wantRequests int64
+	}{
+		{
+			name:         "fixture",
+			group:        "Muse",
+			song:         "Supermassive Black Hole",
+			wantRequests: 1,
+		},
+		{
+			name:         "fixture ignores case and spaces",
+			group:        "muse",
+			song:         "  supermassive   black hole ",
+			wantRequests: 1,
+		},
+		{
+			name:         "unknown song",
+			group:        "Muse",
+			song:         "Uprising",
+			wantErr:      true,
+			wantKind:     errs.KindNotFound,
+			wantRequests: 1,
+		},
+		{
+			name:         "server errors",
+			group:        "Muse",
+			song:         "Supermassive Black Hole",
+			faults:       mockinfo.Faults{ErrorRate: 1, ErrorStatus: http.StatusServiceUnavailable},
+			wantErr:      true,
+			wantKind:     errs.KindUpstreamUnavailable,
+			wantRequests: 3,
+		},
+		{
+			name:         "malformed json",
+			group:        "Muse",
+			song:         "Supermassive Black Hole",
+			faults:       mockinfo.Faults{MalformedRate: 1},
+			wantErr:      true,
+			wantKind:     errs.KindUpstreamUnavailable,
+			wantRequests: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, baseURL := mockinfotest.NewServer(t, fixture)
+			server.SetFaults(tt.faults)
+			api := newTestAPI(baseURL, 2, 10)
+
+			details, err := api.GetSongDetails(context.Background(), tt.group, tt.song)
+
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("err = nil, want %s", tt.wantKind)
+				}
+				if kind := errs.KindOf(err); kind != tt.wantKind {
+					t.Fatalf("kind = %s, want %s (err: %v)", kind, tt.wantKind, err)
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if details.ReleaseDate != fixture.ReleaseDate || details.Text != fixture.Text || details.Link != fixture.Link {
+					t.Fatalf("details = %+v, want fixture %+v", details, fixture)
+				}
+			}
+
+			if got := server.Requests(); got != tt.wantRequests {
+				t.Fatalf("requests = %d, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package external_api
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// StaticProvider резервный поставщик: отдает одни и те же значения для любой песни.
+// В значениях можно использовать {group} и {song} — они заменяются экранированными для URL
+// названиями, например https://www.youtube.com/results?search_query={group}+{song}
+type StaticProvider struct {
+	details dto.SongDetails
+}
+
+func NewStaticProvider(details dto.SongDetails) *StaticProvider {
+	return &StaticProvider{details: details}
+}
+
+// NewStaticProviderFromEnv читает значения из ENRICHMENT_STATIC_RELEASE_DATE, ENRICHMENT_STATIC_TEXT и ENRICHMENT_STATIC_LINK
+func NewStaticProviderFromEnv() *StaticProvider {
+	return NewStaticProvider(dto.SongDetails{
+		ReleaseDate: os.Getenv("ENRICHMENT_STATIC_RELEASE_DATE"),
+		Text:        os.Getenv("ENRICHMENT_STATIC_TEXT"),
+		Link:        os.Getenv("ENRICHMENT_STATIC_LINK"),
+	})
+}
+
+func (p *StaticProvider) Name() string {
+	return ProviderStatic
+}
+
+func (p *StaticProvider) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	if p.details.ReleaseDate == "" && p.details.Text == "" && p.details.Link == "" {
+		return nil, errs.NotFound("резервные значения метаданных не заданы")
+	}
+
+	replacer := strings.NewReplacer("{group}", url.QueryEscape(group), "{song}", url.QueryEscape(song))
+
+	return &dto.SongDetails{
+		ReleaseDate: p.details.ReleaseDate,
+		Text:        replacer.Replace(p.details.Text),
+		Link:        replacer.Replace(p.details.Link),
+	}, nil
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+// @Summary Get groups
+// @Tags groups
+// @Description Get a list of all groups
+// @ID getGroups
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} model.Group
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups [get]
+func (h *Handler) GetGroups(c *gin.Context) {
+	groups, err := h.services.GetGroups(c.Request.Context())
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, groups)
+}
+
+// @Summary Get group by ID
+// @Tags groups
+// @Description Get a group by its ID
+// @ID getGroupById
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Group ID"
+// @Success 200 {object} model.Group
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups/{id} [get]
+func (h *Handler) GetGroupById(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Group ID")
+		return
+	}
+
+	group, err := h.services.GetGroupById(c.Request.Context(), id)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, group)
+}
+
+// @Summary Get group songs
+// @Tags groups
+// @Description Get all songs of a group
+// @ID getGroupSongs
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Group ID"
+// @Success 200 {array} model.Song
+// @Failure 400 {object} errorResponse
+// @Failure 404 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups/{id}/songs [get]
+func (h *Handler) GetGroupSongs(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Group ID")
+		return
+	}
+
+	songs, err := h.services.GetGroupSongs(c.Request.Context(), id)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, songs)
+}
+
+// @Summary Add a new group
+// @Security ApiKeyAuth
+// @Tags groups
+// @Description Add a new group to the catalog
+// @ID addGroup
+// @Accept  json
+// @Produce  json
+// @Param  group body dto.AddGroupRequest true "Details of the group to add"
+// @Success 201 {object} map[string]interface{} "Group created successfully"
+// @Failure 400 {object} errorResponse "Invalid JSON"
+// @Failure 409 {object} errorResponse "Conflicts with existing data"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups [post]
+func (h *Handler) AddGroup(c *gin.Context) {
+	var group dto.AddGroupRequest
+
+	if err := c.ShouldBindJSON(&group); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	id, err := h.services.AddGroup(c.Request.Context(), group)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Group added successfully"})
+}
+
+// @Summary Rename a group
+// @Security ApiKeyAuth
+// @Tags groups
+// @Description Rename an existing group
+// @ID updateGroup
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Group ID"
+// @Param  group body dto.UpdateGroupRequest true "New group name"
+// @Success 200 {object} map[string]interface{} "Group updated successfully"
+// @Failure 400 {object} errorResponse "Invalid JSON"
+// @Failure 404 {object} errorResponse "Group not found"
+// @Failure 409 {object} errorResponse "Conflicts with existing data"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups/{id} [put]
+func (h *Handler) UpdateGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Group ID")
+		return
+	}
+
+	var group dto.UpdateGroupRequest
+
+	if err := c.ShouldBindJSON(&group); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	group.Id = id
+
+	if err := h.services.UpdateGroup(c.Request.Context(), group); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Group updated successfully"})
+}
+
+// @Summary Delete a group
+// @Security ApiKeyAuth
+// @Tags groups
+// @Description Delete a group that has no songs
+// @ID deleteGroup
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Group ID"
+// @Success 200 {object} map[string]interface{} "Group deleted successfully"
+// @Failure 400 {object} errorResponse "Invalid ID"
+// @Failure 404 {object} errorResponse "Group not found"
+// @Failure 409 {object} errorResponse "Conflicts with existing data"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups/{id} [delete]
+func (h *Handler) DeleteGroup(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	if err = h.services.DeleteGroup(c.Request.Context(), id); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Group deleted successfully"})
+}
+
+// @Summary Merge groups
+// @Security ApiKeyAuth
+// @Tags groups
+// @Description Move all songs of the source groups into the target group and delete the sources. With dryRun the affected songs are only reported
+// @ID mergeGroups
+// @Accept  json
+// @Produce  json
+// @Param  merge body dto.MergeGroupsRequest true "Target and source groups"
+// @Success 200 {object} dto.MergeGroupsResult
+// @Failure 400 {object} errorResponse "Invalid JSON"
+// @Failure 404 {object} errorResponse "Group not found"
+// @Failure 409 {object} errorResponse "Conflicts with existing data"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/groups/merge [post]
+func (h *Handler) MergeGroups(c *gin.Context) {
+	var req dto.MergeGroupsRequest
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := h.services.MergeGroups(c.Request.Context(), req)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+// ActorAnonymous автор изменений из запросов без заголовка X-Actor
+const ActorAnonymous = "anonymous"
+
+// RequestTimeout ограничивает время обработки запроса. По истечении timeout контекст
+// запроса отменяется, и незавершенные запросы к БД и внешнему API прерываются
+func RequestTimeout(timeout time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// ChangeAuthor запоминает в контексте запроса автора изменений из заголовка X-Actor
+// и причину из X-Change-Reason. Они попадают в ревизии, которые пишет каждое изменение песни
+func ChangeAuthor() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		change := dto.Change{
+			Actor:  strings.TrimSpace(c.GetHeader("X-Actor")),
+			Reason: strings.TrimSpace(c.GetHeader("X-Change-Reason")),
+		}
+		if change.Actor == "" {
+			change.Actor = ActorAnonymous
+		}
+
+		c.Request = c.Request.WithContext(dto.WithChange(c.Request.Context(), change))
+		c.Next()
+	}
+}
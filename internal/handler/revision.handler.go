@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+// revisionParams разбирает идентификатор песни и номер ревизии из пути
+func (h *Handler) revisionParams(c *gin.Context) (songId, revision int, ok bool) {
+	songId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return 0, 0, false
+	}
+
+	revision, err = strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid revision")
+		return 0, 0, false
+	}
+
+	return songId, revision, true
+}
+
+// @Summary Get song revisions
+// @Tags revisions
+// @Description Get the revision history of a song, newest first. Every change of the song (update, verse edit, enrichment, restore) writes an immutable revision
+// @Description with a snapshot of the fields, the actor from the X-Actor header and the reason from the X-Change-Reason header or the request body
+// @ID getRevisions
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Success 200 {array} model.SongRevision
+// @Failure 400 {object} errorResponse "Invalid Song ID"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/revisions [get]
+func (h *Handler) GetRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	revisions, err := h.services.GetRevisions(c.Request.Context(), id)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// @Summary Get a song revision
+// @Tags revisions
+// @Description Get a single revision of a song
+// @ID getRevision
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  revision path int true "Revision number"
+// @Success 200 {object} model.SongRevision
+// @Failure 400 {object} errorResponse "Invalid Song ID or revision"
+// @Failure 404 {object} errorResponse "Revision not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/revisions/{revision} [get]
+func (h *Handler) GetRevision(c *gin.Context) {
+	songId, revision, ok := h.revisionParams(c)
+	if !ok {
+		return
+	}
+
+	songRevision, err := h.services.GetRevision(c.Request.Context(), songId, revision)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, songRevision)
+}
+
+// @Summary Compare song revisions
+// @Tags revisions
+// @Description Get the fields that differ between two revisions of a song. For text a line diff is returned as well
+// @ID diffRevisions
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  from query int true "Older revision" minimum(1)
+// @Param  to query int true "Newer revision" minimum(1)
+// @Success 200 {object} dto.RevisionDiff
+// @Failure 400 {object} errorResponse "Invalid Song ID or revisions"
+// @Failure 404 {object} errorResponse "Revision not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/revisions/diff [get]
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	var query dto.DiffRevisionsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	diff, err := h.services.DiffRevisions(c.Request.Context(), id, query.From, query.To)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// @Summary Restore a song revision
+// @Security ApiKeyAuth
+// @Tags revisions
+// @Description Bring the song fields back to a past revision. The restore is written as a new revision, history is never rewritten.
+// @Description The text is restored as a whole, so all verses get the verse type
+// @ID restoreRevision
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  revision path int true "Revision to restore"
+// @Param  X-Actor header string false "Author of the change"
+// @Param  restore body dto.RestoreRevisionRequest false "Reason of the restore"
+// @Success 201 {object} model.SongRevision "New revision"
+// @Header  201 {string} Location "URL of the new revision"
+// @Failure 400 {object} errorResponse "Invalid Song ID, revision or JSON"
+// @Failure 404 {object} errorResponse "Revision not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/revisions/{revision}/restore [post]
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	songId, revision, ok := h.revisionParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.RestoreRevisionRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	req.SongId, req.Revision = songId, revision
+
+	restored, err := h.services.RestoreRevision(c.Request.Context(), req)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/song/"+strconv.Itoa(songId)+"/revisions/"+strconv.Itoa(restored.Revision))
+	c.JSON(http.StatusCreated, restored)
+}
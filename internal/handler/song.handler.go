@@ -3,69 +3,111 @@ package handler
 import (
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
 )
 
-type pagination struct {
-	limit, offset int
+type releaseDateFilters struct {
+	releaseDate, releasedAfter, releasedBefore *time.Time
+	decade                                     int
+}
+
+// songsFilterParams параметры списка песен, которые раньше читались из заголовков
+var songsFilterParams = []string{
+	"id", "genre", "song", "releaseDate", "releasedAfter", "releasedBefore", "year", "decade",
+	"text", "link", "groupId", "group", "limit", "offset",
 }
 
 // @Summary Get songs
 // @Tags songs
-// @Description Get a list of songs with optional filters and pagination
+// @Description Get a list of songs with optional filters and pagination.
+// @Description Filters are query parameters. Passing them in HTTP headers of the same name is deprecated: it still works when the query parameter is absent, and such responses carry a Deprecation header.
+// @Description Songs are ordered by the sort parameter, e.g. sort=-releaseDate,group,song (minus means descending; allowed fields: id, song, group, genre, releaseDate), ties are broken by ID. Passing the cursor parameter (empty for the first page) switches to keyset pagination:
+// @Description the response becomes a dto.SongsPage object whose next_cursor is passed to fetch the next page and is absent on the last one
 // @ID getSongs
 // @Accept  json
 // @Produce  json
-// @Param  id header int false "Filter by ID"
-// @Param  genre header string false "Filter by genre"
-// @Param  song header string false "Filter by song name"
-// @Param  releaseDate header string false "Filter by release date"
-// @Param  text header string false "Filter by text content"
-// @Param  link header string false "Filter by link"
-// @Param  groupId header int false "Filter by group ID"
-// @Param  group header string false "Filter by group name"
-// @Param  limit query int false "Pagination limit"
+// @Param  id query int false "Filter by ID"
+// @Param  genre query string false "Filter by genre"
+// @Param  song query string false "Filter by song name"
+// @Param  releaseDate query string false "Filter by exact release date (YYYY-MM-DD or DD.MM.YYYY)"
+// @Param  releasedAfter query string false "Released on or after the date (YYYY-MM-DD or DD.MM.YYYY)"
+// @Param  releasedBefore query string false "Released on or before the date (YYYY-MM-DD or DD.MM.YYYY)"
+// @Param  year query int false "Filter by release year"
+// @Param  decade query string false "Filter by release decade: 1990, 1990s or 90s"
+// @Param  text query string false "Filter by text content"
+// @Param  link query string false "Filter by link"
+// @Param  groupId query int false "Filter by group ID"
+// @Param  group query string false "Filter by group name"
+// @Param  limit query int false "Pagination limit" minimum(0) maximum(100)
 // @Param  offset query int false "Pagination offset"
+// @Param  sort query string false "Comma-separated sort fields, prefix with - for descending"
+// @Param  enrichmentStatus query string false "Filter by enrichment status" Enums(pending, enriched, failed, skipped)
+// @Param  cursor query string false "Opaque pagination cursor from next_cursor"
 // @Success 200 {array} model.Song
+// @Header  200 {string} Deprecation "Set to true when filters were read from deprecated headers"
 // @Failure 400 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/v1/songs [get]
 func (h *Handler) GetSongs(c *gin.Context) {
-	id, _ := strconv.Atoi(c.GetHeader("id"))
-	genre := c.GetHeader("genre")
-	song := c.GetHeader("song")
-	releaseDate := c.GetHeader("releaseDate")
-	text := c.GetHeader("text")
-	link := c.GetHeader("link")
-	groupId, _ := strconv.Atoi(c.GetHeader("groupId"))
-	group := c.GetHeader("group")
-
-	pag, err := h.initPagination(c)
+	var query dto.GetSongsQuery
+
+	if err := h.bindQueryWithHeaderFallback(c, &query, songsFilterParams...); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	dates, err := parseReleaseDateFilters(query)
 	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
 	resp := dto.GetSongsRequest{
-		Id:          id,
-		Genre:       genre,
-		Song:        song,
-		ReleaseDate: releaseDate,
-		Text:        text,
-		Link:        link,
-		GroupId:     groupId,
-		Group:       group,
-		Limit:       pag.limit,
-		Offset:      pag.offset,
+		Id:               query.Id,
+		Genre:            query.Genre,
+		Song:             query.Song,
+		ReleaseDate:      dates.releaseDate,
+		ReleasedAfter:    dates.releasedAfter,
+		ReleasedBefore:   dates.releasedBefore,
+		Year:             query.Year,
+		Decade:           dates.decade,
+		Text:             query.Text,
+		Link:             query.Link,
+		GroupId:          query.GroupId,
+		Group:            query.Group,
+		EnrichmentStatus: query.EnrichmentStatus,
+		Limit:            query.Limit,
+		Offset:           query.Offset,
+		Sort:             query.Sort,
 	}
 
 	h.logger.Info("Fetching songs with filters: ", resp)
 
-	songs, err := h.services.GetSongs(resp)
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		if resp.Offset != 0 {
+			h.newErrorResponse(c, http.StatusBadRequest, "cursor cannot be combined with offset")
+			return
+		}
+		resp.Cursor = cursor
+
+		page, err := h.services.GetSongsPage(c.Request.Context(), resp)
+		if err != nil {
+			h.newServiceErrorResponse(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+		return
+	}
+
+	songs, err := h.services.GetSongs(c.Request.Context(), resp)
 	if err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		h.newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -74,12 +116,15 @@ func (h *Handler) GetSongs(c *gin.Context) {
 
 // @Summary Get song by ID
 // @Tags songs
-// @Description Get a song by its ID
+// @Description Get a song by its ID. The ETag header holds the song version; with a matching If-None-Match the response is 304 without a body
 // @ID getSongById
 // @Accept  json
 // @Produce  json
 // @Param  id path int true "Song ID"
+// @Param  If-None-Match header string false "ETag of the cached song"
 // @Success 200 {object} model.Song
+// @Header  200 {string} ETag "Song version"
+// @Success 304 "Song has not changed"
 // @Failure 400 {object} errorResponse
 // @Failure 404 {object} errorResponse
 // @Failure 500 {object} errorResponse
@@ -92,9 +137,15 @@ func (h *Handler) GetSongById(c *gin.Context) {
 		return
 	}
 
-	song, err := h.services.GetSongById(id)
+	song, err := h.services.GetSongById(c.Request.Context(), id)
 	if err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("ETag", songETag(song.Version))
+	if matchesIfNoneMatch(c.GetHeader("If-None-Match"), song.Version) {
+		c.Status(http.StatusNotModified)
 		return
 	}
 
@@ -104,15 +155,24 @@ func (h *Handler) GetSongById(c *gin.Context) {
 // @Summary Get song lyrics
 // @Security ApiKeyAuth
 // @Tags songs
-// @Description Get lyrics of a song by its ID with optional pagination
+// @Description Get a page of song lyrics split into verses. Verses are separated by one or more blank lines; CRLF line endings are accepted.
+// @Description Pages are numbered from 1; a page past the end of the lyrics is empty. total_verses is the number of verses in the whole song and has_more tells whether there are verses after this page.
+// @Description Passing page and limit in HTTP headers of the same name is deprecated, as is passing the page number in offset; such responses carry a Deprecation header.
+// @Description With lang the verses of the translation are returned; a regional variant of the stored language also matches (en-GB gets the en translation).
+// @Description When the translation has as many verses as the original, original holds the original verses of the same page side by side
 // @ID getSongLyrics
 // @Accept  json
 // @Produce  json
 // @Param  id path int true "Song ID"
-// @Param  limit header int false "Pagination limit"
-// @Param  offset header int false "Pagination offset"
-// @Success 200 {object} map[string][]string "Lyrics of the song"
-// @Failure 400 {object} errorResponse "Invalid Song ID"
+// @Param  page query int false "Page number, starting from 1" default(1) minimum(1)
+// @Param  limit query int false "Verses per page" default(10) minimum(1) maximum(100)
+// @Param  offset query int false "Deprecated alias for page"
+// @Param  lang query string false "Translation language, a BCP-47 tag such as en or pt-BR"
+// @Success 200 {object} dto.SongLyrics "Page of lyrics"
+// @Header  200 {string} Deprecation "Set to true when deprecated pagination parameters were used"
+// @Header  200 {string} Content-Language "Language of the translation when lang is given"
+// @Failure 400 {object} errorResponse "Invalid Song ID, pagination parameters or language"
+// @Failure 404 {object} errorResponse "Song or translation not found"
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/v1/song/{id}/lyrics [get]
@@ -123,39 +183,52 @@ func (h *Handler) GetSongLyrics(c *gin.Context) {
 		return
 	}
 
-	pag, err := h.initPagination(c)
-	if err != nil {
+	var query dto.GetSongLyricsQuery
+	if err := h.bindQueryWithHeaderFallback(c, &query, "page", "limit", "offset"); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid pagination parameters: "+err.Error())
 		return
 	}
 
-	resp := dto.GetSongLyricsRequest{
-		Id:     id,
-		Limit:  pag.limit,
-		Offset: pag.offset,
+	resp := dto.GetSongLyricsRequest{Id: id, Lang: query.Lang}
+	if query.Limit != nil {
+		resp.Limit = *query.Limit
+	}
+
+	switch {
+	case query.Page != nil:
+		resp.Page = *query.Page
+	case query.Offset != nil:
+		// Раньше номер страницы передавался в offset
+		c.Header("Deprecation", "true")
+		resp.Page = max(*query.Offset, 1)
 	}
 
-	verses, err := h.services.GetSongLyrics(resp)
+	lyrics, err := h.services.GetSongLyrics(c.Request.Context(), resp)
 	if err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		h.newServiceErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"verses": verses,
-	})
+	if lyrics.Lang != "" {
+		c.Header("Content-Language", lyrics.Lang)
+	}
+	c.JSON(http.StatusOK, lyrics)
 }
 
 // @Summary Delete a song
 // @Security ApiKeyAuth
 // @Tags songs
-// @Description Delete an existing song
+// @Description Move a song to the trash. It can be restored until the trash retention period expires, then it is purged for good.
+// @Description With If-Match the song is deleted only if its version still matches
 // @ID deleteSong
 // @Accept  json
 // @Produce  json
 // @Param  id path int true "Song ID"
+// @Param  If-Match header string false "ETag of the song version being deleted"
 // @Success 200 {object} map[string]interface{} "Song deleted successfully"
 // @Failure 400 {object} errorResponse "Invalid ID"
 // @Failure 404 {object} errorResponse "Song not found"
+// @Failure 412 {object} errorResponse "Song has been changed"
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/v1/song/{id} [delete]
@@ -166,8 +239,8 @@ func (h *Handler) DeleteSong(c *gin.Context) {
 		return
 	}
 
-	if err = h.services.DeleteSong(id); err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	if err = h.services.DeleteSong(c.Request.Context(), id, parseIfMatch(c.GetHeader("If-Match"))); err != nil {
+		h.newServiceErrorResponse(c, err)
 		return
 	}
 
@@ -177,88 +250,191 @@ func (h *Handler) DeleteSong(c *gin.Context) {
 // @Summary Update a song
 // @Security ApiKeyAuth
 // @Tags songs
-// @Description Update an existing song
+// @Description Update an existing song. Every update writes a new revision of the song with the actor from the X-Actor header
+// @Description and the reason from the request body or the X-Change-Reason header.
+// @Description With If-Match the song is updated only if its version still matches, otherwise the response is 412. The ETag header holds the new version
 // @ID updateSong
 // @Accept  json
 // @Produce  json
+// @Param  X-Actor header string false "Author of the change, anonymous by default"
+// @Param  X-Change-Reason header string false "Reason of the change"
+// @Param  If-Match header string false "ETag of the song version being updated"
 // @Param  song body dto.UpdateSongRequest true "Updated song details"
 // @Success 200 {object} map[string]interface{} "Song updated successfully"
+// @Header  200 {string} ETag "New song version"
 // @Failure 400 {object} errorResponse "Invalid JSON"
 // @Failure 404 {object} errorResponse "Song not found"
+// @Failure 412 {object} errorResponse "Song has been changed"
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/v1/song [put]
 func (h *Handler) UpdateSong(c *gin.Context) {
 	var song dto.UpdateSongRequest
 
-	if err := c.BindJSON(&song); err != nil {
+	if err := c.ShouldBindJSON(&song); err != nil {
 		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	if err := h.services.UpdateSong(song); err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+	song.IfMatch = parseIfMatch(c.GetHeader("If-Match"))
+
+	version, err := h.services.UpdateSong(c.Request.Context(), song)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
 		return
 	}
 
+	c.Header("ETag", songETag(version))
 	c.JSON(http.StatusOK, map[string]interface{}{"message": "Song updated successfully"})
 }
 
 // @Summary Add a new song
 // @Security ApiKeyAuth
 // @Tags songs
-// @Description Add a new song to the catalog
+// @Description Add a new song to the catalog. The song is stored right away; release date, lyrics and link are then filled in from the song info service in the background according to the enrich policy:
+// @Description never leaves the song as sent, missing (default) fills in only empty fields, always replaces fields with non-empty upstream values. Genre is stored as given.
+// @Description The song gets enrichmentStatus pending, or skipped when there is nothing to fill in; once enriched, its enrichedFields list the fields taken from the upstream
 // @ID addSong
 // @Accept  json
 // @Produce  json
 // @Param  song body dto.AddSongRequest true "Details of the song to add"
-// @Success 201 {object} map[string]interface{} "Song created successfully"
+// @Param  enrich query string false "Merge policy for song info service data" Enums(never, missing, always)
+// @Success 202 {object} dto.AddSongResult "Song accepted"
+// @Header  202 {string} Location "URL of the created song"
 // @Failure 400 {object} errorResponse "Invalid JSON"
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/v1/song [post]
 func (h *Handler) AddSong(c *gin.Context) {
 	var song dto.AddSongRequest
+	var query dto.EnrichQuery
 
-	if err := c.BindJSON(&song); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON"})
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	id, err := h.services.AddSong(song)
+	if err := c.ShouldBindJSON(&song); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid JSON: "+err.Error())
+		return
+	}
+	song.Enrich = query.Enrich
+
+	result, err := h.services.AddSong(c.Request.Context(), song)
 	if err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		h.newServiceErrorResponse(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"id": id, "message": "Song added successfully"})
+	c.Header("Location", "/api/v1/song/"+strconv.Itoa(result.Id))
+	c.JSON(http.StatusAccepted, result)
+}
+
+// @Summary Enrich a song
+// @Security ApiKeyAuth
+// @Tags songs
+// @Description Queue the song for enrichment from the song info service again, resetting its attempt counter.
+// @Description Without enrich the policy given when the song was added is kept; never is replaced with missing
+// @ID enrichSong
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  enrich query string false "Merge policy for song info service data" Enums(missing, always)
+// @Success 202 {object} map[string]interface{} "Song enrichment scheduled"
+// @Failure 400 {object} errorResponse "Invalid Song ID"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/enrich [post]
+func (h *Handler) EnrichSong(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	var query dto.EnrichQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := h.services.RequestEnrichment(c.Request.Context(), id, query.Enrich); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"id":               id,
+		"enrichmentStatus": model.EnrichmentPending,
+		"message":          "Song enrichment scheduled",
+	})
 }
 
 // @Summary Search songs
 // @Tags songs
-// @Description Search songs by query string
+// @Description Search songs by query string. The default fulltext mode searches song name, group, genre and lyrics, supports web search syntax ("quoted phrases", OR, -exclusion) and orders results by relevance; each hit carries highlights: snippets of the matched fields with the terms wrapped in <mark></mark>. The fuzzy mode tolerates typos in song and group names using trigram similarity
 // @ID searchSongs
 // @Accept  json
 // @Produce  json
-// @Param  query query string true "Search query"
-// @Success 200 {array} model.Song
+// @Param  query path string true "Search query"
+// @Param  mode query string false "Search mode" Enums(fulltext, fuzzy)
+// @Param  limit query int false "Maximum number of results in fuzzy mode"
+// @Param  sort query string false "Comma-separated sort fields, prefix with - for descending; relevance breaks ties"
+// @Success 200 {array} model.SongSearchResult
 // @Failure 400 {object} errorResponse
 // @Failure 500 {object} errorResponse
 // @Failure default {object} errorResponse
 // @Router /api/v1/songs/search/{query} [get]
 func (h *Handler) SearchSongs(c *gin.Context) {
-	query := c.Query("query")
+	var req dto.SearchSongsRequest
 
-	if query == "" {
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	req.Query = c.Param("query")
+	if req.Query == "" {
 		h.newErrorResponse(c, http.StatusBadRequest, "Missing query parameter")
 		return
 	}
 
-	songs, err := h.services.SearchSongs(query)
+	songs, err := h.services.SearchSongs(c.Request.Context(), req)
 	if err != nil {
-		h.newErrorResponse(c, http.StatusInternalServerError, err.Error())
+		h.newServiceErrorResponse(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, songs)
 }
+
+// @Summary Suggest songs and groups
+// @Tags songs
+// @Description Type-ahead suggestions: song and group names starting with or similar to the prefix
+// @ID suggestSongs
+// @Accept  json
+// @Produce  json
+// @Param  prefix query string true "Typed prefix"
+// @Param  limit query int false "Maximum number of suggestions"
+// @Success 200 {array} model.Suggestion
+// @Failure 400 {object} errorResponse
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/songs/suggest [get]
+func (h *Handler) SuggestSongs(c *gin.Context) {
+	var req dto.SuggestRequest
+
+	if err := c.ShouldBindQuery(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	suggestions, err := h.services.SuggestSongs(c.Request.Context(), req)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suggestions)
+}
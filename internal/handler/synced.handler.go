@@ -0,0 +1,157 @@
+package handler
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+// maxLRCSize ограничение размера импортируемого LRC
+const maxLRCSize = 1 << 20
+
+// @Summary Get synced lyrics
+// @Tags songs
+// @Description Get the time-synced lyrics of a song: lines with start times, as in an LRC file. A line with empty text is a pause.
+// @Description With at the response is the line playing at that position of the song together with the next line; before the first line index is -1 and line is null.
+// @Description format=lrc exports the lyrics as an LRC file with title and artist tags
+// @ID getSyncedLyrics
+// @Accept  json
+// @Produce  json,plain
+// @Param  id path int true "Song ID"
+// @Param  at query string false "Playback position: mm:ss.xx or seconds, e.g. 01:23.45 or 83.45"
+// @Param  format query string false "Response format" Enums(json, lrc) default(json)
+// @Success 200 {object} dto.SyncedLyrics "Synced lyrics; dto.SyncedLineAt when at is given, LRC text when format is lrc"
+// @Failure 400 {object} errorResponse "Invalid Song ID, position or format"
+// @Failure 404 {object} errorResponse "Song or synced lyrics not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/lyrics/synced [get]
+func (h *Handler) GetSyncedLyrics(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	var query dto.GetSyncedLyricsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	switch {
+	case query.Format == dto.SyncedFormatLRC && query.At != "":
+		h.newErrorResponse(c, http.StatusBadRequest, "at cannot be combined with format=lrc")
+
+	case query.Format == dto.SyncedFormatLRC:
+		data, err := h.services.ExportLRC(c.Request.Context(), id)
+		if err != nil {
+			h.newServiceErrorResponse(c, err)
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="song-`+strconv.Itoa(id)+`.lrc"`)
+		c.Data(http.StatusOK, "text/plain; charset=utf-8", []byte(data))
+
+	case query.At != "":
+		at, err := dto.ParsePlaybackPosition(query.At)
+		if err != nil {
+			h.newServiceErrorResponse(c, err)
+			return
+		}
+
+		line, err := h.services.GetSyncedLineAt(c.Request.Context(), id, at)
+		if err != nil {
+			h.newServiceErrorResponse(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, line)
+
+	default:
+		lyrics, err := h.services.GetSyncedLyrics(c.Request.Context(), id)
+		if err != nil {
+			h.newServiceErrorResponse(c, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, lyrics)
+	}
+}
+
+// @Summary Import synced lyrics
+// @Security ApiKeyAuth
+// @Tags songs
+// @Description Replace the time-synced lyrics of a song with an LRC file sent as the request body. A line may carry several timestamps;
+// @Description the offset tag is applied to the timestamps, other tags are not stored. The plain text of the song is not changed
+// @ID importSyncedLyrics
+// @Accept  plain
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  lrc body string true "Lyrics in LRC format"
+// @Success 200 {object} dto.SyncedLyrics
+// @Failure 400 {object} errorResponse "Invalid Song ID or LRC"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 413 {object} errorResponse "LRC is larger than 1 MiB"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/lyrics/synced [put]
+func (h *Handler) ImportSyncedLyrics(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxLRCSize))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.newErrorResponse(c, http.StatusRequestEntityTooLarge, "LRC is larger than 1 MiB")
+			return
+		}
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lyrics, err := h.services.ImportLRC(c.Request.Context(), id, string(data))
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, lyrics)
+}
+
+// @Summary Delete synced lyrics
+// @Security ApiKeyAuth
+// @Tags songs
+// @Description Delete the time-synced lyrics of a song. The plain text of the song is kept
+// @ID deleteSyncedLyrics
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Success 200 {object} map[string]interface{} "Synced lyrics deleted successfully"
+// @Failure 400 {object} errorResponse "Invalid Song ID"
+// @Failure 404 {object} errorResponse "Song or synced lyrics not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/lyrics/synced [delete]
+func (h *Handler) DeleteSyncedLyrics(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	if err := h.services.DeleteSyncedLyrics(c.Request.Context(), id); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Synced lyrics deleted successfully"})
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+// @Summary Get song translations
+// @Tags translations
+// @Description Get all translations of the song lyrics ordered by language
+// @ID getTranslations
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Success 200 {array} model.Translation
+// @Failure 400 {object} errorResponse "Invalid Song ID"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/translations [get]
+func (h *Handler) GetTranslations(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	translations, err := h.services.GetTranslations(c.Request.Context(), id)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translations)
+}
+
+// @Summary Get a translation
+// @Tags translations
+// @Description Get the translation of the song lyrics into a language. The language must match exactly after canonicalization (en-us is en-US)
+// @ID getTranslation
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  lang path string true "BCP-47 language tag"
+// @Success 200 {object} model.Translation
+// @Failure 400 {object} errorResponse "Invalid Song ID or language"
+// @Failure 404 {object} errorResponse "Translation not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/translations/{lang} [get]
+func (h *Handler) GetTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	translation, err := h.services.GetTranslation(c.Request.Context(), id, c.Param("lang"))
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, translation)
+}
+
+// @Summary Add or replace a translation
+// @Security ApiKeyAuth
+// @Tags translations
+// @Description Save the translation of the song lyrics into a language, replacing an existing one. Verses are separated by blank lines as in the song text;
+// @Description a translation with as many verses as the original is returned side by side with it by GET /api/v1/song/{id}/lyrics?lang=
+// @ID saveTranslation
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  lang path string true "BCP-47 language tag"
+// @Param  translation body dto.SaveTranslationRequest true "Translated lyrics"
+// @Success 200 {object} model.Translation "Translation replaced"
+// @Success 201 {object} model.Translation "Translation created"
+// @Header  201 {string} Location "URL of the translation"
+// @Failure 400 {object} errorResponse "Invalid JSON, language or text"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/translations/{lang} [put]
+func (h *Handler) SaveTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	var req dto.SaveTranslationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.SongId, req.Lang = id, c.Param("lang")
+
+	translation, created, err := h.services.SaveTranslation(c.Request.Context(), req)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusOK, translation)
+		return
+	}
+
+	c.Header("Location", "/api/v1/song/"+strconv.Itoa(id)+"/translations/"+translation.Lang)
+	c.JSON(http.StatusCreated, translation)
+}
+
+// @Summary Delete a translation
+// @Security ApiKeyAuth
+// @Tags translations
+// @Description Delete the translation of the song lyrics into a language
+// @ID deleteTranslation
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  lang path string true "BCP-47 language tag"
+// @Success 200 {object} map[string]interface{} "Translation deleted successfully"
+// @Failure 400 {object} errorResponse "Invalid Song ID or language"
+// @Failure 404 {object} errorResponse "Translation not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/translations/{lang} [delete]
+func (h *Handler) DeleteTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	if err := h.services.DeleteTranslation(c.Request.Context(), id, c.Param("lang")); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Translation deleted successfully"})
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// @Summary Get the trash
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description Get the deleted songs, most recently deleted first. A song stays in the trash until purgeAt, then it is removed for good
+// @ID getTrash
+// @Accept  json
+// @Produce  json
+// @Success 200 {array} dto.TrashedSong
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/songs/trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	songs, err := h.services.GetTrash(c.Request.Context())
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, songs)
+}
+
+// @Summary Restore a deleted song
+// @Security ApiKeyAuth
+// @Tags trash
+// @Description Bring a song back from the trash together with its verses, translations and revisions
+// @ID restoreSong
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Success 200 {object} model.Song
+// @Failure 400 {object} errorResponse "Invalid ID"
+// @Failure 404 {object} errorResponse "Song not found in the trash"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/songs/trash/{id}/restore [post]
+func (h *Handler) RestoreSong(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid ID")
+		return
+	}
+
+	song, err := h.services.RestoreSong(c.Request.Context(), id)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, song)
+}
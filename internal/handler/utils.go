@@ -1,20 +1,84 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
 )
 
+// errorResponse тело ошибки в формате RFC 7807 (application/problem+json)
+type errorResponse struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+}
+
 func (h *Handler) newErrorResponse(c *gin.Context, statusCode int, message string) {
 	h.logger.Error(message)
-	
-	c.JSON(statusCode, gin.H{
-		"error": message,
+
+	c.Header("Content-Type", "application/problem+json")
+	c.AbortWithStatusJSON(statusCode, errorResponse{
+		Type:     "about:blank",
+		Title:    http.StatusText(statusCode),
+		Status:   statusCode,
+		Detail:   message,
+		Instance: c.Request.URL.Path,
 	})
 }
 
+// newServiceErrorResponse отвечает статусом, соответствующим виду доменной ошибки.
+// Клиент получает только сообщение доменной ошибки: ее причина (текст драйвера базы,
+// ответ внешнего API) и подробности внутренних ошибок только пишутся в лог
+func (h *Handler) newServiceErrorResponse(c *gin.Context, err error) {
+	switch ctxErr := c.Request.Context().Err(); {
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		h.newErrorResponse(c, http.StatusGatewayTimeout, "request timed out")
+		return
+	case errors.Is(ctxErr, context.Canceled):
+		h.logger.Warnf("Запрос %s %s отменен клиентом: %v", c.Request.Method, c.Request.URL.Path, err)
+		c.Abort()
+		return
+	}
+
+	statusCode := http.StatusInternalServerError
+
+	switch errs.KindOf(err) {
+	case errs.KindNotFound:
+		statusCode = http.StatusNotFound
+	case errs.KindConflict:
+		statusCode = http.StatusConflict
+	case errs.KindValidation:
+		statusCode = http.StatusBadRequest
+	case errs.KindUpstreamUnavailable:
+		statusCode = http.StatusServiceUnavailable
+	case errs.KindPreconditionFailed:
+		statusCode = http.StatusPreconditionFailed
+	default:
+		h.logger.Errorf("Внутренняя ошибка при обработке %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+		h.newErrorResponse(c, statusCode, "internal server error")
+		return
+	}
+
+	var domainErr *errs.Error
+	errors.As(err, &domainErr)
+	if domainErr.Err != nil {
+		h.logger.Errorf("Ошибка при обработке %s %s: %v", c.Request.Method, c.Request.URL.Path, err)
+	}
+
+	h.newErrorResponse(c, statusCode, domainErr.Message)
+}
+
 func (h *Handler) newSuccessResponse(c *gin.Context, statusCode int, data interface{}) {
 	h.logger.Info(data)
 
@@ -23,22 +87,124 @@ func (h *Handler) newSuccessResponse(c *gin.Context, statusCode int, data interf
 	})
 }
 
-func (h *Handler) initPagination(c *gin.Context) (*pagination, error) {
-	limit, err := strconv.Atoi(c.GetHeader("limit"))
-	if err != nil {
-		if c.GetHeader("limit") != "" {
-			h.newErrorResponse(c, http.StatusBadRequest, "Invalid limit parameter")
-			return nil, err
+// bindQueryWithHeaderFallback связывает query-параметры со структурой и проверяет их.
+// Параметр из names, которого нет в query, берется из одноименного заголовка — так
+// его передавали раньше. Такой способ устарел, поэтому в ответ добавляется заголовок Deprecation
+func (h *Handler) bindQueryWithHeaderFallback(c *gin.Context, obj interface{}, names ...string) error {
+	values := c.Request.URL.Query()
+
+	var fromHeaders []string
+	for _, name := range names {
+		if _, ok := values[name]; ok {
+			continue
+		}
+
+		if header := c.GetHeader(name); header != "" {
+			values.Set(name, header)
+			fromHeaders = append(fromHeaders, name)
+		}
+	}
+
+	if len(fromHeaders) > 0 {
+		c.Header("Deprecation", "true")
+		h.logger.Warnf("Параметры %v переданы в заголовках, следует использовать query-параметры", fromHeaders)
+	}
+
+	if err := binding.MapFormWithTag(obj, values, "form"); err != nil {
+		return err
+	}
+
+	return binding.Validator.ValidateStruct(obj)
+}
+
+// parseReleaseDateFilters разбирает фильтры по дате выхода
+func parseReleaseDateFilters(query dto.GetSongsQuery) (*releaseDateFilters, error) {
+	var filters releaseDateFilters
+
+	dates := []struct {
+		name   string
+		value  string
+		target **time.Time
+	}{
+		{"releaseDate", query.ReleaseDate, &filters.releaseDate},
+		{"releasedAfter", query.ReleasedAfter, &filters.releasedAfter},
+		{"releasedBefore", query.ReleasedBefore, &filters.releasedBefore},
+	}
+
+	for _, date := range dates {
+		if date.value == "" {
+			continue
+		}
+
+		parsed, err := dto.ParseDate(date.value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s parameter: %w", date.name, err)
+		}
+		*date.target = &parsed
+	}
+
+	if query.Decade != "" {
+		decade, err := dto.ParseDecade(query.Decade)
+		if err != nil {
+			return nil, fmt.Errorf("invalid decade parameter: %w", err)
 		}
+		filters.decade = decade
 	}
 
-	offset, err := strconv.Atoi(c.GetHeader("offset"))
+	return &filters, nil
+}
+
+// songETag формирует сильный ETag песни из ее версии
+func songETag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// etagVersion извлекает версию песни из сильного ETag
+func etagVersion(tag string) (int, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.Atoi(tag[1 : len(tag)-1])
 	if err != nil {
-		if c.GetHeader("offset") != "" {
-			h.newErrorResponse(c, http.StatusBadRequest, "Invalid offset parameter")
-			return nil, err
+		return 0, false
+	}
+
+	return version, true
+}
+
+// parseIfMatch разбирает заголовок If-Match. Без заголовка и для * условия нет.
+// If-Match использует сильное сравнение, поэтому слабые и чужие ETag не совпадают ни с одной версией
+func parseIfMatch(header string) dto.IfMatch {
+	header = strings.TrimSpace(header)
+	if header == "" || header == "*" {
+		return nil
+	}
+
+	versions := dto.IfMatch{}
+	for _, tag := range strings.Split(header, ",") {
+		if version, ok := etagVersion(strings.TrimSpace(tag)); ok {
+			versions = append(versions, version)
 		}
 	}
 
-	return &pagination{limit: limit, offset: offset}, nil
-}
\ No newline at end of file
+	return versions
+}
+
+// matchesIfNoneMatch сообщает, совпадает ли версия с заголовком If-None-Match.
+// Здесь сравнение слабое: W/"3" совпадает с версией 3
+func matchesIfNoneMatch(header string, version int) bool {
+	header = strings.TrimSpace(header)
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tagVersion, ok := etagVersion(tag); ok && tagVersion == version {
+			return true
+		}
+	}
+
+	return false
+}
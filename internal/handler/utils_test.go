@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitNop()
+	gin.SetMode(gin.TestMode)
+
+	os.Exit(m.Run())
+}
+
+func TestNewServiceErrorResponse(t *testing.T) {
+	cause := errors.New(`pq: duplicate key value violates unique constraint "groups_name_key"`)
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantDetail string
+	}{
+		{name: "not found", err: errs.NotFound("песня с id %d не найдена", 7), wantStatus: http.StatusNotFound, wantDetail: "песня с id 7 не найдена"},
+		{name: "conflict with driver cause", err: errs.Wrap(errs.KindConflict, cause, "запись уже существует"), wantStatus: http.StatusConflict, wantDetail: "запись уже существует"},
+		{name: "wrapped validation", err: fmt.Errorf("сохранение: %w", errs.Wrap(errs.KindValidation, cause, "некорректные данные")), wantStatus: http.StatusBadRequest, wantDetail: "некорректные данные"},
+		{name: "upstream cause", err: errs.UpstreamUnavailable(errors.New("dial tcp 10.0.0.1:443: connection refused"), "внешний API временно недоступен"), wantStatus: http.StatusServiceUnavailable, wantDetail: "внешний API временно недоступен"},
+		{name: "precondition failed", err: errs.PreconditionFailed("песня %d была изменена", 1), wantStatus: http.StatusPreconditionFailed, wantDetail: "песня 1 была изменена"},
+		{name: "internal", err: cause, wantStatus: http.StatusInternalServerError, wantDetail: "internal server error"},
+	}
+
+	h := NewHandler(nil, logging.GetLogger())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(rec)
+			c.Request = httptest.NewRequest(http.MethodGet, "/api/v1/groups", nil)
+
+			h.newServiceErrorResponse(c, tt.err)
+
+			var problem errorResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("decode %s: %v", rec.Body, err)
+			}
+			if rec.Code != tt.wantStatus || problem.Status != tt.wantStatus || problem.Detail != tt.wantDetail {
+				t.Fatalf("status %d, problem %+v, want %d %q", rec.Code, problem, tt.wantStatus, tt.wantDetail)
+			}
+			if strings.Contains(rec.Body.String(), "pq:") || strings.Contains(rec.Body.String(), "dial tcp") {
+				t.Fatalf("cause leaked to the client: %s", rec.Body)
+			}
+		})
+	}
+}
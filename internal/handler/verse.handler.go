@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+// verseParams разбирает идентификаторы песни и куплета из пути
+func (h *Handler) verseParams(c *gin.Context) (songId, verseId int, ok bool) {
+	songId, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return 0, 0, false
+	}
+
+	verseId, err = strconv.Atoi(c.Param("verseId"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Verse ID")
+		return 0, 0, false
+	}
+
+	return songId, verseId, true
+}
+
+// @Summary Get song verses
+// @Tags verses
+// @Description Get the verses of a song in order. The song text is these verses joined with a blank line
+// @ID getSongVerses
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Success 200 {array} model.Verse
+// @Failure 400 {object} errorResponse "Invalid Song ID"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/verses [get]
+func (h *Handler) GetSongVerses(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	verses, err := h.services.GetSongVerses(c.Request.Context(), id)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verses)
+}
+
+// @Summary Get a verse
+// @Tags verses
+// @Description Get a single verse of a song
+// @ID getVerse
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  verseId path int true "Verse ID"
+// @Success 200 {object} model.Verse
+// @Failure 400 {object} errorResponse "Invalid ID"
+// @Failure 404 {object} errorResponse "Verse not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/verses/{verseId} [get]
+func (h *Handler) GetVerse(c *gin.Context) {
+	songId, verseId, ok := h.verseParams(c)
+	if !ok {
+		return
+	}
+
+	verse, err := h.services.GetVerse(c.Request.Context(), songId, verseId)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verse)
+}
+
+// @Summary Add a verse
+// @Security ApiKeyAuth
+// @Tags verses
+// @Description Insert a verse at the given position, shifting the following verses, or append it when position is omitted. Type defaults to verse.
+// @Description The verse text may span several lines but must not contain blank lines
+// @ID addVerse
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  verse body dto.AddVerseRequest true "Verse to add"
+// @Success 201 {object} model.Verse
+// @Header  201 {string} Location "URL of the created verse"
+// @Failure 400 {object} errorResponse "Invalid JSON, text or position"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/verses [post]
+func (h *Handler) AddVerse(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	var req dto.AddVerseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.SongId = id
+
+	verse, err := h.services.AddVerse(c.Request.Context(), req)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.Header("Location", "/api/v1/song/"+strconv.Itoa(id)+"/verses/"+strconv.Itoa(verse.ID))
+	c.JSON(http.StatusCreated, verse)
+}
+
+// @Summary Edit a verse
+// @Security ApiKeyAuth
+// @Tags verses
+// @Description Change the type and/or text of a verse without resubmitting the whole song
+// @ID updateVerse
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  verseId path int true "Verse ID"
+// @Param  verse body dto.UpdateVerseRequest true "Verse changes"
+// @Success 200 {object} map[string]interface{} "Verse updated successfully"
+// @Failure 400 {object} errorResponse "Invalid JSON or text"
+// @Failure 404 {object} errorResponse "Verse not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/verses/{verseId} [put]
+func (h *Handler) UpdateVerse(c *gin.Context) {
+	songId, verseId, ok := h.verseParams(c)
+	if !ok {
+		return
+	}
+
+	var req dto.UpdateVerseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.SongId, req.VerseId = songId, verseId
+
+	if err := h.services.UpdateVerse(c.Request.Context(), req); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Verse updated successfully"})
+}
+
+// @Summary Reorder verses
+// @Security ApiKeyAuth
+// @Tags verses
+// @Description Put the verses of a song in the given order. verseIds must list every verse of the song exactly once
+// @ID reorderVerses
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  order body dto.ReorderVersesRequest true "New verse order"
+// @Success 200 {array} model.Verse
+// @Failure 400 {object} errorResponse "Invalid JSON or order"
+// @Failure 404 {object} errorResponse "Song not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/verses/order [put]
+func (h *Handler) ReorderVerses(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, "Invalid Song ID")
+		return
+	}
+
+	var req dto.ReorderVersesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.newErrorResponse(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	req.SongId = id
+
+	verses, err := h.services.ReorderVerses(c.Request.Context(), req)
+	if err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, verses)
+}
+
+// @Summary Delete a verse
+// @Security ApiKeyAuth
+// @Tags verses
+// @Description Delete a verse; the following verses move up
+// @ID deleteVerse
+// @Accept  json
+// @Produce  json
+// @Param  id path int true "Song ID"
+// @Param  verseId path int true "Verse ID"
+// @Success 200 {object} map[string]interface{} "Verse deleted successfully"
+// @Failure 400 {object} errorResponse "Invalid ID"
+// @Failure 404 {object} errorResponse "Verse not found"
+// @Failure 500 {object} errorResponse
+// @Failure default {object} errorResponse
+// @Router /api/v1/song/{id}/verses/{verseId} [delete]
+func (h *Handler) DeleteVerse(c *gin.Context) {
+	songId, verseId, ok := h.verseParams(c)
+	if !ok {
+		return
+	}
+
+	if err := h.services.DeleteVerse(c.Request.Context(), songId, verseId); err != nil {
+		h.newServiceErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, map[string]interface{}{"message": "Verse deleted successfully"})
+}
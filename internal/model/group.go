@@ -0,0 +1,6 @@
+package model
+
+type Group struct {
+	ID   int    `json:"id" db:"id"`
+	Name string `json:"name" db:"name"`
+}
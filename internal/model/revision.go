@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// SongRevision неизменяемый снимок полей песни после очередного изменения
+type SongRevision struct {
+	SongID   int    `json:"songId" db:"song_id"`
+	Revision int    `json:"revision" db:"revision"`
+	Song     string `json:"song" db:"song"`
+	// Group название группы на момент изменения
+	Group       string `json:"group" db:"group_name"`
+	Genre       string `json:"genre" db:"genre"`
+	ReleaseDate string `json:"releaseDate" db:"release_date"`
+	Text        string `json:"text" db:"text"`
+	Link        string `json:"link" db:"link"`
+	// Actor автор изменения: заголовок X-Actor запроса или system для фоновых задач
+	Actor     string    `json:"actor" db:"actor"`
+	Reason    string    `json:"reason" db:"reason"`
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+}
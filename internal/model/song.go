@@ -1,12 +1,132 @@
 package model
 
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Статусы дополнения песни данными внешнего API
+const (
+	EnrichmentPending  = "pending"
+	EnrichmentEnriched = "enriched"
+	EnrichmentFailed   = "failed"
+	EnrichmentSkipped  = "skipped"
+)
+
+// Политики слияния полей песни с данными внешнего API
+const (
+	// EnrichNever не обращаться к внешнему API
+	EnrichNever = "never"
+	// EnrichMissing заполнять только поля, которые клиент оставил пустыми
+	EnrichMissing = "missing"
+	// EnrichAlways заменять поля клиента непустыми значениями внешнего API
+	EnrichAlways = "always"
+)
+
+// Поля песни, которые может заполнить внешний API
+const (
+	FieldReleaseDate = "releaseDate"
+	FieldText        = "text"
+	FieldLink        = "link"
+)
+
+// Song песня в том виде, в котором ее отдает API. Дата выхода выбирается в запросах
+// как "releaseDate" в кавычках: без них postgres приводит имя столбца к нижнему регистру
+// и sqlx не находит поле
 type Song struct {
-	ID          int    `json:"id" db:"id"`
-	Genre       string `json:"genre" db:"genre"`
-	Song        string `json:"song" db:"song"`
-	ReleaseDate string `json:"releaseDate" db:"releaseDate"`
-	Text        string `json:"text" db:"text"`
-	Link        string `json:"link" db:"link"`
-	GroupID     int    `json:"groupId" db:"group_id"`
-	Group       string `json:"group" db:"group_name"`
+	ID               int    `json:"id" db:"id"`
+	Genre            string `json:"genre" db:"genre"`
+	Song             string `json:"song" db:"song"`
+	ReleaseDate      string `json:"releaseDate" db:"releaseDate"`
+	Text             string `json:"text" db:"text"`
+	Link             string `json:"link" db:"link"`
+	GroupID          int    `json:"groupId" db:"group_id"`
+	Group            string `json:"group" db:"group_name"`
+	EnrichmentStatus string `json:"enrichmentStatus" db:"enrichment_status"`
+	// EnrichedFields поля, значения которых взяты из внешнего API
+	EnrichedFields pq.StringArray `json:"enrichedFields" db:"enriched_fields" swaggertype:"array,string"`
+	// EnrichmentSources поставщик данных для каждого поля из EnrichedFields
+	EnrichmentSources FieldSources `json:"enrichmentSources" db:"enrichment_sources" swaggertype:"object,string"`
+	// HasSyncedLyrics есть ли у песни синхронизированный текст (GET /song/{id}/lyrics/synced)
+	HasSyncedLyrics bool `json:"hasSyncedLyrics" db:"has_synced_lyrics"`
+	// Version растет при каждом изменении песни, ETag ответа GET /song/{id}
+	Version int `json:"version" db:"version"`
+	// DeletedAt время перемещения песни в корзину, только у удаленных песен
+	DeletedAt *time.Time `json:"deletedAt,omitempty" db:"deleted_at"`
+}
+
+// FieldSources сопоставляет полю песни имя поставщика, заполнившего его. Хранится в jsonb
+type FieldSources map[string]string
+
+func (f FieldSources) Value() (driver.Value, error) {
+	if f == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(f)
+}
+
+func (f *FieldSources) Scan(src interface{}) error {
+	var data []byte
+	switch v := src.(type) {
+	case []byte:
+		data = v
+	case string:
+		data = []byte(v)
+	case nil:
+		*f = FieldSources{}
+		return nil
+	default:
+		return fmt.Errorf("неподдерживаемый тип источников полей: %T", src)
+	}
+
+	sources := FieldSources{}
+	if err := json.Unmarshal(data, &sources); err != nil {
+		return err
+	}
+	*f = sources
+
+	return nil
+}
+
+// EnrichmentTask песня, взятая фоновым обработчиком для дополнения данными внешнего API
+type EnrichmentTask struct {
+	SongID   int    `db:"id"`
+	Song     string `db:"song"`
+	Group    string `db:"group_name"`
+	Attempts int    `db:"enrichment_attempts"`
+	Policy   string `db:"enrichment_policy"`
+	// Текущие значения полей; дата выхода в формате ISO-8601
+	ReleaseDate string `db:"releaseDate"`
+	Text        string `db:"text"`
+	Link        string `db:"link"`
+	// Version версия песни на момент выбора: данные внешнего API сохраняются, только если
+	// песню с тех пор не изменили
+	Version int `db:"version"`
+}
+
+type SongSearchResult struct {
+	Song
+	Rank       float64          `json:"rank" db:"rank"`
+	Highlights SearchHighlights `json:"highlights" db:"highlights"`
+}
+
+// SearchHighlights содержит фрагменты полей, совпавших с поисковым запросом.
+// Найденные слова обрамлены тегами <mark></mark>, несовпавшие поля отсутствуют
+type SearchHighlights struct {
+	Song  *string `json:"song,omitempty" db:"song"`
+	Group *string `json:"group,omitempty" db:"group"`
+	Text  *string `json:"text,omitempty" db:"text"`
+	Genre *string `json:"genre,omitempty" db:"genre"`
+}
+
+// Suggestion вариант автодополнения: название песни или группы
+type Suggestion struct {
+	Type  string  `json:"type" db:"type"`
+	ID    int     `json:"id" db:"id"`
+	Value string  `json:"value" db:"value"`
+	Score float64 `json:"score" db:"score"`
 }
@@ -0,0 +1,8 @@
+package model
+
+// SyncedLine строка синхронизированного текста, которая начинает звучать через StartMs
+// миллисекунд от начала песни. Пустой Text означает паузу
+type SyncedLine struct {
+	StartMs int64  `json:"startMs" db:"start_ms"`
+	Text    string `json:"text" db:"text"`
+}
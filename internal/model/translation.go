@@ -0,0 +1,12 @@
+package model
+
+import "time"
+
+// Translation перевод текста песни на язык Lang (тег BCP-47). Куплеты перевода
+// разделяются пустой строкой, как в тексте песни
+type Translation struct {
+	SongID    int       `json:"songId" db:"song_id"`
+	Lang      string    `json:"lang" db:"lang"`
+	Text      string    `json:"text" db:"text"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
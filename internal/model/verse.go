@@ -0,0 +1,19 @@
+package model
+
+// Типы куплетов
+const (
+	VerseTypeVerse  = "verse"
+	VerseTypeChorus = "chorus"
+	VerseTypeBridge = "bridge"
+	VerseTypeIntro  = "intro"
+	VerseTypeOutro  = "outro"
+)
+
+// Verse куплет песни. Позиции куплетов песни идут подряд, начиная с 1
+type Verse struct {
+	ID       int    `json:"id" db:"id"`
+	SongID   int    `json:"songId" db:"song_id"`
+	Position int    `json:"position" db:"position"`
+	Type     string `json:"type" db:"type"`
+	Text     string `json:"text" db:"text"`
+}
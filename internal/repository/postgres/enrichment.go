@@ -0,0 +1,211 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"slices"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type EnrichmentPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewEnrichmentPostgres(db *sqlx.DB) *EnrichmentPostgres {
+	return &EnrichmentPostgres{db: db, logger: logging.GetLogger()}
+}
+
+// ClaimSongsForEnrichment забирает до limit песен, ожидающих дополнения, и откладывает
+// их следующую попытку на lease. Так строки не держатся заблокированными во время запроса
+// к внешнему API, а песни обработчика, упавшего посреди работы, будут взяты повторно.
+// SKIP LOCKED позволяет нескольким экземплярам сервиса разбирать очередь параллельно
+func (e EnrichmentPostgres) ClaimSongsForEnrichment(ctx context.Context, limit int, lease time.Duration) ([]model.EnrichmentTask, error) {
+	tasks := []model.EnrichmentTask{}
+
+	query := `
+		WITH claimed AS (
+			SELECT id
+			FROM songs
+			WHERE enrichment_status = 'pending' AND next_enrichment_at <= now() AND deleted_at IS NULL
+			ORDER BY next_enrichment_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		UPDATE songs s
+		SET enrichment_attempts = s.enrichment_attempts + 1,
+			next_enrichment_at = now() + $2::bigint * INTERVAL '1 millisecond'
+		FROM claimed
+		WHERE s.id = claimed.id
+		RETURNING s.id, s.song, COALESCE((SELECT name FROM groups WHERE id = s.group_id), '') as group_name,
+				  s.enrichment_attempts, s.enrichment_policy,
+				  COALESCE(TO_CHAR(s.releaseDate, 'YYYY-MM-DD'), '') as "releaseDate",
+				  COALESCE(s.text, '') as text, COALESCE(s.link, '') as link, s.version
+	`
+
+	if err := e.db.SelectContext(ctx, &tasks, query, limit, lease.Milliseconds()); err != nil {
+		e.logger.Errorf("Ошибка при выборе песен для дополнения: %v", err)
+		return nil, err
+	}
+
+	return tasks, nil
+}
+
+// CompleteEnrichment сохраняет данные внешнего API в полях fields, отмечает их в enriched_fields
+// и запоминает поставщиков из details.Sources. Остальные поля не меняются. Новый текст
+// заменяет куплеты песни. Поля выбраны по состоянию песни версии version; если песню
+// с тех пор изменили, данные не сохраняются, а песня сразу возвращается в очередь,
+// чтобы их выбрали заново. В этом случае возвращается PreconditionFailed
+func (e EnrichmentPostgres) CompleteEnrichment(ctx context.Context, id, version int, details dto.SongDetails, fields []string) error {
+	sources := model.FieldSources{}
+	for _, field := range fields {
+		if source, ok := details.Sources[field]; ok {
+			sources[field] = source
+		}
+	}
+
+	query := `
+		UPDATE songs
+		SET releaseDate = CASE WHEN 'releaseDate' = ANY($4) THEN NULLIF($1, '')::date ELSE releaseDate END,
+			text = CASE WHEN 'text' = ANY($4) THEN $2 ELSE text END,
+			link = CASE WHEN 'link' = ANY($4) THEN $3 ELSE link END,
+			enriched_fields = ARRAY(SELECT DISTINCT f FROM unnest(enriched_fields || $4::text[]) f ORDER BY f),
+			enrichment_sources = enrichment_sources || $5::jsonb,
+			enrichment_status = 'enriched',
+			next_enrichment_at = NULL,
+			enrichment_error = NULL,
+			version = version + 1
+		WHERE id = $6
+	`
+
+	tx, err := e.db.BeginTxx(ctx, nil)
+	if err != nil {
+		e.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Песня могла попасть в корзину во время запроса к внешнему API, поэтому deleted_at не проверяется
+	var current int
+	err = tx.QueryRowContext(ctx, "SELECT version FROM songs WHERE id = $1 FOR UPDATE", id).Scan(&current)
+	if err != nil {
+		return translateError(err, "песня с id %d не найдена", id)
+	}
+
+	if current != version {
+		_, err = tx.ExecContext(ctx, "UPDATE songs SET next_enrichment_at = now() WHERE id = $1 AND enrichment_status = 'pending'", id)
+		if err != nil {
+			e.logger.Errorf("Ошибка при возврате песни %d в очередь дополнения: %v", id, err)
+			return err
+		}
+
+		if err = tx.Commit(); err != nil {
+			e.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+			return err
+		}
+
+		return errs.PreconditionFailed("песня %d изменена во время дополнения: версия %d, ожидалась %d", id, current, version)
+	}
+
+	if _, err = tx.ExecContext(ctx, query, details.ReleaseDate, details.Text, details.Link, pq.Array(fields), sources, id); err != nil {
+		e.logger.Errorf("Ошибка при сохранении данных внешнего API для песни %d: %v", id, err)
+		return translateError(err, "песня с id %d не найдена", id)
+	}
+
+	if slices.Contains(fields, model.FieldText) {
+		if err = replaceVerses(ctx, tx, id, details.Text); err != nil {
+			e.logger.Errorf("Ошибка при сохранении куплетов песни %d: %v", id, err)
+			return err
+		}
+	}
+
+	if len(fields) > 0 {
+		if err = recordRevision(ctx, tx, id); err != nil {
+			e.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", id, err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		e.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// FailEnrichment записывает ошибку дополнения. Если retryAt задан, песня остается
+// в очереди до этого момента, иначе получает статус failed. Версия песни растет только
+// при смене статуса: повторные попытки не должны мешать клиентам, изменяющим песню
+func (e EnrichmentPostgres) FailEnrichment(ctx context.Context, id int, reason string, retryAt *time.Time) error {
+	status := model.EnrichmentFailed
+	if retryAt != nil {
+		status = model.EnrichmentPending
+	}
+
+	// Тип $1 указан явно: параметр используется и в присваивании, и в сравнении,
+	// и без приведения postgres может вывести для него разные типы
+	query := `
+		UPDATE songs
+		SET enrichment_status = $1::varchar, next_enrichment_at = $2, enrichment_error = $3,
+			version = version + CASE WHEN enrichment_status = $1::varchar THEN 0 ELSE 1 END
+		WHERE id = $4
+	`
+
+	result, err := e.db.ExecContext(ctx, query, status, retryAt, reason, id)
+	if err != nil {
+		e.logger.Errorf("Ошибка при сохранении ошибки дополнения песни %d: %v", id, err)
+		return err
+	}
+
+	return expectAffected(result, "песня с id %d не найдена", id)
+}
+
+// RequestEnrichment ставит песню в очередь на дополнение заново со сброшенным счетчиком
+// попыток. Пустая policy сохраняет прежнюю политику слияния, а never заменяется на missing:
+// явный запрос дополнения важнее запрета, заданного при добавлении. Версия растет и для песни,
+// уже ожидающей дополнения: так CompleteEnrichment отклонит дополнение, начатое по старой политике
+func (e EnrichmentPostgres) RequestEnrichment(ctx context.Context, id int, policy string) error {
+	query := `
+		UPDATE songs
+		SET enrichment_status = 'pending',
+			enrichment_policy = COALESCE(NULLIF($2, ''), NULLIF(enrichment_policy, 'never'), 'missing'),
+			enrichment_attempts = 0,
+			next_enrichment_at = now(), enrichment_error = NULL,
+			version = version + 1
+		WHERE id = $1 AND deleted_at IS NULL
+	`
+
+	result, err := e.db.ExecContext(ctx, query, id, policy)
+	if err != nil {
+		e.logger.Errorf("Ошибка при постановке песни %d в очередь дополнения: %v", id, err)
+		return err
+	}
+
+	return expectAffected(result, "песня с id %d не найдена", id)
+}
+
+// expectAffected возвращает NotFound, если запрос не изменил ни одной строки
+func expectAffected(result sql.Result, notFoundFmt string, args ...interface{}) error {
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errs.NotFound(notFoundFmt, args...)
+	}
+
+	return nil
+}
@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// translateError переводит ошибки драйвера в доменные ошибки сервиса.
+// notFound используется как сообщение, если запрос не вернул строк
+func translateError(err error, notFound string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return errs.NotFound(notFound, args...)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		return errs.Wrap(errs.KindConflict, err, "запись уже существует")
+	case "foreign_key_violation":
+		return errs.Wrap(errs.KindConflict, err, "нарушена связь с другой записью")
+	case "not_null_violation", "check_violation":
+		return errs.Wrap(errs.KindValidation, err, "некорректные данные")
+	}
+
+	// Класс 22 — ошибки данных: неверная дата, слишком длинная строка и т.п.
+	if pqErr.Code.Class() == "22" {
+		return errs.Wrap(errs.KindValidation, err, "некорректные данные")
+	}
+
+	return err
+}
@@ -0,0 +1,322 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type GroupsPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewGroupsPostgres(db *sqlx.DB) *GroupsPostgres {
+	return &GroupsPostgres{db: db, logger: logging.GetLogger()}
+}
+
+func (g GroupsPostgres) GetGroups(ctx context.Context) ([]model.Group, error) {
+	groups := []model.Group{}
+
+	if err := g.db.SelectContext(ctx, &groups, "SELECT id, name FROM groups ORDER BY name, id"); err != nil {
+		g.logger.Errorf("Ошибка при получении групп: %v", err)
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (g GroupsPostgres) GetGroupById(ctx context.Context, id int) (model.Group, error) {
+	var group model.Group
+
+	if err := g.db.GetContext(ctx, &group, "SELECT id, name FROM groups WHERE id = $1", id); err != nil {
+		g.logger.Errorf("Ошибка при получении группы по ID %d: %v", id, err)
+		return model.Group{}, translateError(err, "группа с id %d не найдена", id)
+	}
+
+	return group, nil
+}
+
+func (g GroupsPostgres) AddGroup(ctx context.Context, req dto.AddGroupRequest) (int, error) {
+	var groupId int
+
+	tx, err := g.db.BeginTxx(ctx, nil)
+	if err != nil {
+		g.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return 0, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = ensureGroupNameFree(ctx, tx, req.Name, 0); err != nil {
+		return 0, err
+	}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO groups (name) VALUES ($1) RETURNING id", req.Name).Scan(&groupId)
+	if err != nil {
+		g.logger.Errorf("Ошибка при создании группы: %v", err)
+		return 0, translateError(err, "группа не создана")
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return 0, err
+	}
+
+	return groupId, nil
+}
+
+// UpdateGroup переименовывает группу. Название группы входит в представление ее песен,
+// включая песни в корзине, поэтому каждая из них получает новую версию и ревизию
+func (g GroupsPostgres) UpdateGroup(ctx context.Context, req dto.UpdateGroupRequest) error {
+	tx, err := g.db.BeginTxx(ctx, nil)
+	if err != nil {
+		g.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = ensureGroupNameFree(ctx, tx, req.Name, req.Id); err != nil {
+		return err
+	}
+
+	var name string
+	err = tx.QueryRowContext(ctx, "SELECT name FROM groups WHERE id = $1 FOR UPDATE", req.Id).Scan(&name)
+	if err != nil {
+		g.logger.Errorf("Ошибка при поиске группы %d: %v", req.Id, err)
+		return translateError(err, "группа с id %d не найдена", req.Id)
+	}
+
+	if name == req.Name {
+		return tx.Commit()
+	}
+
+	if _, err = tx.ExecContext(ctx, "UPDATE groups SET name = $1 WHERE id = $2", req.Name, req.Id); err != nil {
+		g.logger.Errorf("Ошибка при переименовании группы: %v", err)
+		return translateError(err, "группа с id %d не найдена", req.Id)
+	}
+
+	var songIds []int
+	err = tx.SelectContext(ctx, &songIds, "UPDATE songs SET version = version + 1 WHERE group_id = $1 RETURNING id", req.Id)
+	if err != nil {
+		g.logger.Errorf("Ошибка при обновлении версий песен группы: %v", err)
+		return err
+	}
+
+	if err = recordRevisions(ctx, tx, songIds); err != nil {
+		g.logger.Errorf("Ошибка при сохранении ревизий песен группы %d: %v", req.Id, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteGroup удаляет группу без песен. Строка группы блокируется до конца транзакции:
+// проверка внешнего ключа при добавлении песни в группу ждет этой блокировки, поэтому
+// песня не может появиться между подсчетом и удалением
+func (g GroupsPostgres) DeleteGroup(ctx context.Context, id int) error {
+	tx, err := g.db.BeginTxx(ctx, nil)
+	if err != nil {
+		g.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var groupId int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM groups WHERE id = $1 FOR UPDATE", id).Scan(&groupId)
+	if err != nil {
+		g.logger.Errorf("Ошибка при поиске группы %d: %v", id, err)
+		return translateError(err, "группа с id %d не найдена", id)
+	}
+
+	var songsCount int
+	err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM songs WHERE group_id = $1", id).Scan(&songsCount)
+	if err != nil {
+		g.logger.Errorf("Ошибка при подсчете песен группы: %v", err)
+		return err
+	}
+
+	if songsCount > 0 {
+		err = errs.Conflict("у группы с id %d есть песни (%d), удаление невозможно", id, songsCount)
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM groups WHERE id = $1", id); err != nil {
+		g.logger.Errorf("Ошибка при удалении группы %d: %v", id, err)
+		return translateError(err, "группа с id %d не найдена", id)
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// MergeGroups переносит все песни исходных групп, включая песни в корзине, в целевую и удаляет
+// исходные группы. В режиме DryRun транзакция откатывается, а в результате возвращаются затронутые песни
+func (g GroupsPostgres) MergeGroups(ctx context.Context, req dto.MergeGroupsRequest) (dto.MergeGroupsResult, error) {
+	result := dto.MergeGroupsResult{
+		TargetId:  req.TargetId,
+		SourceIds: req.SourceIds,
+		DryRun:    req.DryRun,
+		Songs:     []model.Song{},
+	}
+
+	tx, err := g.db.BeginTxx(ctx, nil)
+	if err != nil {
+		g.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+	defer func() {
+		if err != nil || req.DryRun {
+			tx.Rollback()
+		}
+	}()
+
+	var targetId int
+	err = tx.QueryRowContext(ctx, "SELECT id FROM groups WHERE id = $1 FOR UPDATE", req.TargetId).Scan(&targetId)
+	if err == sql.ErrNoRows {
+		err = errs.NotFound("целевая группа с id %d не найдена", req.TargetId)
+		return dto.MergeGroupsResult{}, err
+	} else if err != nil {
+		g.logger.Errorf("Ошибка при поиске целевой группы: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+
+	var foundIds []int
+	err = tx.SelectContext(ctx, &foundIds, "SELECT id FROM groups WHERE id = ANY($1) FOR UPDATE", pq.Array(req.SourceIds))
+	if err != nil {
+		g.logger.Errorf("Ошибка при поиске исходных групп: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+
+	if len(foundIds) != len(req.SourceIds) {
+		err = errs.NotFound("исходные группы не найдены: %v", missingIds(req.SourceIds, foundIds))
+		return dto.MergeGroupsResult{}, err
+	}
+
+	query := `
+		SELECT s.id, s.song, s.genre, COALESCE(TO_CHAR(s.releaseDate, 'DD.MM.YYYY'), '') as "releaseDate", s.text, s.link,
+			   s.group_id, g.name as group_name, s.enrichment_status, s.enriched_fields, s.enrichment_sources,
+			   EXISTS (SELECT 1 FROM song_synced_lines l WHERE l.song_id = s.id) as has_synced_lyrics, s.version, s.deleted_at
+		FROM songs s
+		LEFT JOIN groups g ON s.group_id = g.id
+		WHERE s.group_id = ANY($1)
+		ORDER BY s.id
+		FOR UPDATE OF s
+	`
+
+	err = tx.SelectContext(ctx, &result.Songs, query, pq.Array(req.SourceIds))
+	if err != nil {
+		g.logger.Errorf("Ошибка при получении песен исходных групп: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+	result.MovedSongs = len(result.Songs)
+
+	if req.DryRun {
+		return result, nil
+	}
+
+	var songIds []int
+	err = tx.SelectContext(ctx, &songIds, "UPDATE songs SET group_id = $1, version = version + 1 WHERE group_id = ANY($2) RETURNING id", req.TargetId, pq.Array(req.SourceIds))
+	if err != nil {
+		g.logger.Errorf("Ошибка при переносе песен в группу %d: %v", req.TargetId, err)
+		return dto.MergeGroupsResult{}, err
+	}
+
+	if err = recordRevisions(ctx, tx, songIds); err != nil {
+		g.logger.Errorf("Ошибка при сохранении ревизий перенесенных песен: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "DELETE FROM groups WHERE id = ANY($1)", pq.Array(req.SourceIds))
+	if err != nil {
+		g.logger.Errorf("Ошибка при удалении исходных групп: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		g.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return dto.MergeGroupsResult{}, err
+	}
+
+	g.logger.Infof("Группы %v объединены в группу %d, перенесено песен: %d", req.SourceIds, req.TargetId, result.MovedSongs)
+
+	return result, nil
+}
+
+// missingIds возвращает идентификаторы из ids, которых нет в found
+func missingIds(ids, found []int) []int {
+	foundSet := make(map[int]struct{}, len(found))
+	for _, id := range found {
+		foundSet[id] = struct{}{}
+	}
+
+	var missing []int
+	for _, id := range ids {
+		if _, ok := foundSet[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	return missing
+}
+
+// ensureGroupNameFree проверяет, что название не занято другой группой без учета регистра.
+// Проверка нужна только для понятного сообщения: одновременные вставку и переименование
+// отклоняет уникальный индекс по lower(name), и translateError превращает нарушение в конфликт
+func ensureGroupNameFree(ctx context.Context, tx *sqlx.Tx, name string, exceptId int) error {
+	var existingId int
+
+	err := tx.QueryRowContext(ctx, "SELECT id FROM groups WHERE lower(name) = lower($1) AND id <> $2 LIMIT 1", name, exceptId).Scan(&existingId)
+	if err == sql.ErrNoRows {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return errs.Conflict("группа с названием %q уже существует (id %d)", name, existingId)
+}
+
+// groupIdByName находит группу по названию без учета регистра или создает ее.
+// Если ту же группу одновременно создает другая транзакция, вставка дожидается ее
+// и ничего не добавляет, тогда группа перечитывается
+func groupIdByName(ctx context.Context, tx *sqlx.Tx, name string) (int, error) {
+	var groupId int
+
+	err := tx.QueryRowContext(ctx, "SELECT id FROM groups WHERE lower(name) = lower($1)", name).Scan(&groupId)
+	if err != sql.ErrNoRows {
+		return groupId, err
+	}
+
+	err = tx.QueryRowContext(ctx, "INSERT INTO groups (name) VALUES ($1) ON CONFLICT ((lower(name))) DO NOTHING RETURNING id", name).Scan(&groupId)
+	if err == sql.ErrNoRows {
+		err = tx.QueryRowContext(ctx, "SELECT id FROM groups WHERE lower(name) = lower($1)", name).Scan(&groupId)
+	}
+
+	return groupId, err
+}
@@ -0,0 +1,92 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type RevisionsPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewRevisionsPostgres(db *sqlx.DB) *RevisionsPostgres {
+	return &RevisionsPostgres{db: db, logger: logging.GetLogger()}
+}
+
+const revisionColumns = `
+	song_id, revision, COALESCE(song, '') as song, COALESCE(group_name, '') as group_name,
+	COALESCE(genre, '') as genre, COALESCE(TO_CHAR(release_date, 'DD.MM.YYYY'), '') as release_date,
+	COALESCE(text, '') as text, COALESCE(link, '') as link, actor, reason, created_at
+`
+
+// GetRevisions возвращает ревизии песни, начиная с последней
+func (r RevisionsPostgres) GetRevisions(ctx context.Context, songId int) ([]model.SongRevision, error) {
+	revisions := []model.SongRevision{}
+
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)", songId).Scan(&exists); err != nil {
+		r.logger.Errorf("Ошибка при проверке песни %d: %v", songId, err)
+		return nil, err
+	}
+	if !exists {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	query := "SELECT " + revisionColumns + " FROM song_revisions WHERE song_id = $1 ORDER BY revision DESC"
+
+	if err := r.db.SelectContext(ctx, &revisions, query, songId); err != nil {
+		r.logger.Errorf("Ошибка при получении ревизий песни %d: %v", songId, err)
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (r RevisionsPostgres) GetRevision(ctx context.Context, songId, revision int) (model.SongRevision, error) {
+	var songRevision model.SongRevision
+
+	query := "SELECT " + revisionColumns + " FROM song_revisions WHERE song_id = " + activeSongId + " AND revision = $2"
+
+	if err := r.db.GetContext(ctx, &songRevision, query, songId, revision); err != nil {
+		r.logger.Errorf("Ошибка при получении ревизии %d песни %d: %v", revision, songId, err)
+		return model.SongRevision{}, translateError(err, "ревизия %d песни %d не найдена", revision, songId)
+	}
+
+	return songRevision, nil
+}
+
+// recordRevision сохраняет текущее состояние песни новой ревизией с автором и причиной
+// из контекста. Вызывается в транзакции изменения после блокировки строки песни,
+// поэтому номера ревизий не пересекаются
+func recordRevision(ctx context.Context, tx *sqlx.Tx, songId int) error {
+	change := dto.ChangeFrom(ctx)
+
+	query := `
+		INSERT INTO song_revisions (song_id, revision, song, group_name, genre, release_date, text, link, actor, reason)
+		SELECT s.id, COALESCE((SELECT MAX(r.revision) FROM song_revisions r WHERE r.song_id = s.id), 0) + 1,
+			   s.song, g.name, s.genre, s.releaseDate, s.text, s.link, $2, $3
+		FROM songs s
+		LEFT JOIN groups g ON s.group_id = g.id
+		WHERE s.id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, songId, change.Actor, change.Reason)
+	return err
+}
+
+// recordRevisions сохраняет ревизии нескольких песен, строки которых уже заблокированы
+func recordRevisions(ctx context.Context, tx *sqlx.Tx, songIds []int) error {
+	for _, songId := range songIds {
+		if err := recordRevision(ctx, tx, songId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
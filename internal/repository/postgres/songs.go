@@ -1,12 +1,12 @@
 package postgres
 
 import (
-	"database/sql"
-	"errors"
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
@@ -21,15 +21,16 @@ func NewSongsPostgres(db *sqlx.DB) *SongsPostgres {
 	return &SongsPostgres{db: db, logger: logging.GetLogger()}
 }
 
-func (s SongsPostgres) GetSongs(req dto.GetSongsRequest) ([]model.Song, error) {
-	var songs []model.Song
+func (s SongsPostgres) GetSongs(ctx context.Context, req dto.GetSongsRequest) ([]model.Song, error) {
+	songs := []model.Song{}
 
 	query := `
-		SELECT s.id, s.song, s.genre, TO_CHAR(s.releaseDate, 'DD.MM.YYYY') as releaseDate, s.text, s.link, 
-			   s.group_id, g.name as group_name
+		SELECT s.id, s.song, s.genre, COALESCE(TO_CHAR(s.releaseDate, 'DD.MM.YYYY'), '') as "releaseDate", s.text, s.link, 
+			   s.group_id, g.name as group_name, s.enrichment_status, s.enriched_fields, s.enrichment_sources,
+			   EXISTS (SELECT 1 FROM song_synced_lines l WHERE l.song_id = s.id) as has_synced_lyrics, s.version
 		FROM songs s
 		LEFT JOIN groups g ON s.group_id = g.id
-		WHERE 1=1
+		WHERE s.deleted_at IS NULL
 	`
 
 	params := []interface{}{}
@@ -53,12 +54,37 @@ func (s SongsPostgres) GetSongs(req dto.GetSongsRequest) ([]model.Song, error) {
 		paramCount++
 	}
 
-	if req.ReleaseDate != "" {
-		query += fmt.Sprintf(" AND TO_CHAR(s.releaseDate, 'DD.MM.YYYY') = $%d", paramCount)
-		params = append(params, req.ReleaseDate)
+	// Даты сравниваются как DATE, чтобы использовался индекс idx_songs_release_date
+	if req.ReleaseDate != nil {
+		query += fmt.Sprintf(" AND s.releaseDate = $%d::date", paramCount)
+		params = append(params, req.ReleaseDate.Format(dto.ISODateLayout))
 		paramCount++
 	}
 
+	if req.ReleasedAfter != nil {
+		query += fmt.Sprintf(" AND s.releaseDate >= $%d::date", paramCount)
+		params = append(params, req.ReleasedAfter.Format(dto.ISODateLayout))
+		paramCount++
+	}
+
+	if req.ReleasedBefore != nil {
+		query += fmt.Sprintf(" AND s.releaseDate <= $%d::date", paramCount)
+		params = append(params, req.ReleasedBefore.Format(dto.ISODateLayout))
+		paramCount++
+	}
+
+	if req.Year != 0 {
+		query += fmt.Sprintf(" AND s.releaseDate >= $%d::date AND s.releaseDate < $%d::date", paramCount, paramCount+1)
+		params = append(params, fmt.Sprintf("%04d-01-01", req.Year), fmt.Sprintf("%04d-01-01", req.Year+1))
+		paramCount += 2
+	}
+
+	if req.Decade != 0 {
+		query += fmt.Sprintf(" AND s.releaseDate >= $%d::date AND s.releaseDate < $%d::date", paramCount, paramCount+1)
+		params = append(params, fmt.Sprintf("%04d-01-01", req.Decade), fmt.Sprintf("%04d-01-01", req.Decade+10))
+		paramCount += 2
+	}
+
 	if req.Text != "" {
 		query += fmt.Sprintf(" AND s.text ILIKE $%d", paramCount)
 		params = append(params, "%"+req.Text+"%")
@@ -83,7 +109,31 @@ func (s SongsPostgres) GetSongs(req dto.GetSongsRequest) ([]model.Song, error) {
 		paramCount++
 	}
 
-	query += " ORDER BY s.id"
+	if req.EnrichmentStatus != "" {
+		query += fmt.Sprintf(" AND s.enrichment_status = $%d", paramCount)
+		params = append(params, req.EnrichmentStatus)
+		paramCount++
+	}
+
+	if req.After != nil {
+		condition, keysetParams, nextParam, err := songsKeysetCondition(req.SortBy, req.After.Values, params, paramCount)
+		if err != nil {
+			return nil, err
+		}
+		query += " AND " + condition
+		params = keysetParams
+		paramCount = nextParam
+	}
+
+	if len(req.SortBy) > 0 {
+		orderBy, err := songsOrderBy(req.SortBy)
+		if err != nil {
+			return nil, err
+		}
+		query += " ORDER BY " + orderBy
+	} else {
+		query += " ORDER BY s.id"
+	}
 
 	if req.Limit != 0 {
 		query += fmt.Sprintf(" LIMIT $%d", paramCount)
@@ -96,20 +146,22 @@ func (s SongsPostgres) GetSongs(req dto.GetSongsRequest) ([]model.Song, error) {
 		}
 	}
 
-	if err := s.db.Select(&songs, query, params...); err != nil {
+	if err := s.db.SelectContext(ctx, &songs, query, params...); err != nil {
 		s.logger.Errorf("Ошибка при получении песен: %v", err)
-		return nil, err
+		return nil, translateError(err, "песни не найдены")
 	}
 
 	return songs, nil
 }
 
-func (s SongsPostgres) AddSong(req dto.AddSongRequest) (int, error) {
+// AddSong сохраняет песню с политикой слияния req.Enrich. Песня со статусом pending
+// сразу попадает в очередь дополнения данными внешнего API
+func (s SongsPostgres) AddSong(ctx context.Context, req dto.AddSongRequest, enrichmentStatus string) (int, error) {
 	var songId int
 	var groupId int
 
 	// Начинаем транзакцию
-	tx, err := s.db.Beginx()
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		s.logger.Errorf("Ошибка при начале транзакции: %v", err)
 		return 0, err
@@ -120,25 +172,33 @@ func (s SongsPostgres) AddSong(req dto.AddSongRequest) (int, error) {
 		}
 	}()
 
-	err = tx.QueryRow("SELECT id FROM groups WHERE name = $1", req.Group).Scan(&groupId)
-	if err == sql.ErrNoRows {
-		// Группа не существует, создаем новую
-		err = tx.QueryRow("INSERT INTO groups (name) VALUES ($1) RETURNING id", req.Group).Scan(&groupId)
-		if err != nil {
-			s.logger.Errorf("Ошибка при создании группы: %v", err)
-			return 0, err
-		}
-	} else if err != nil {
-		s.logger.Errorf("Ошибка при поиске группы: %v", err)
-		return 0, err
+	// Группа находится по названию или создается
+	if groupId, err = groupIdByName(ctx, tx, req.Group); err != nil {
+		s.logger.Errorf("Ошибка при поиске или создании группы: %v", err)
+		return 0, translateError(err, "группа не создана")
 	}
 
-	query := `INSERT INTO songs (song, genre, releaseDate, text, link, group_id) 
-              VALUES ($1, $2, TO_DATE($3, 'DD.MM.YYYY'), $4, $5, $6) RETURNING id`
+	// Тип $7 указан явно: без приведения postgres выводит для одного параметра text
+	// из сравнения и varchar из столбца и отклоняет запрос
+	query := `INSERT INTO songs (song, genre, releaseDate, text, link, group_id,
+                                 enrichment_status, enrichment_policy, next_enrichment_at) 
+              VALUES ($1, $2, NULLIF($3, '')::date, $4, $5, $6, $7::varchar, $8, CASE WHEN $7::varchar = 'pending' THEN now() END)
+              RETURNING id`
 
-	err = tx.QueryRow(query, req.Song, "Unknown", req.ReleaseDate, req.Text, req.Link, groupId).Scan(&songId)
+	err = tx.QueryRowContext(ctx, query, req.Song, req.Genre, req.ReleaseDate, req.Text, req.Link, groupId,
+		enrichmentStatus, req.Enrich).Scan(&songId)
 	if err != nil {
 		s.logger.Errorf("Ошибка при добавлении песни: %v", err)
+		return 0, translateError(err, "песня не добавлена")
+	}
+
+	if err = replaceVerses(ctx, tx, songId, req.Text); err != nil {
+		s.logger.Errorf("Ошибка при сохранении куплетов песни %d: %v", songId, err)
+		return 0, err
+	}
+
+	if err = recordRevision(ctx, tx, songId); err != nil {
+		s.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", songId, err)
 		return 0, err
 	}
 
@@ -151,11 +211,22 @@ func (s SongsPostgres) AddSong(req dto.AddSongRequest) (int, error) {
 	return songId, nil
 }
 
-func (s SongsPostgres) UpdateSong(req dto.UpdateSongRequest) error {
-	tx, err := s.db.Beginx()
+// selectSongFields текущие значения полей песни, которые меняет UpdateSong, в формате запроса
+const selectSongFields = `
+	SELECT COALESCE(s.song, ''), COALESCE(s.genre, ''), COALESCE(TO_CHAR(s.releaseDate, 'YYYY-MM-DD'), ''),
+		   COALESCE(s.text, ''), COALESCE(s.link, ''), COALESCE(g.name, '')
+	FROM songs s
+	LEFT JOIN groups g ON s.group_id = g.id
+	WHERE s.id = $1
+`
+
+// UpdateSong изменяет переданные поля песни и возвращает ее новую версию. Если задан
+// req.IfMatch, версия сверяется под блокировкой строки, чтобы не затереть чужие изменения
+func (s SongsPostgres) UpdateSong(ctx context.Context, req dto.UpdateSongRequest) (int, error) {
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
 		s.logger.Errorf("Ошибка при начале транзакции: %v", err)
-		return err
+		return 0, err
 	}
 
 	defer func() {
@@ -164,6 +235,34 @@ func (s SongsPostgres) UpdateSong(req dto.UpdateSongRequest) error {
 		}
 	}()
 
+	version, err := lockSongVersion(ctx, tx, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = req.IfMatch.Check(req.Id, version); err != nil {
+		return 0, err
+	}
+
+	var current dto.SongFields
+	err = tx.QueryRowContext(ctx, selectSongFields, req.Id).Scan(
+		&current.Song, &current.Genre, &current.ReleaseDate, &current.Text, &current.Link, &current.Group,
+	)
+	if err != nil {
+		s.logger.Errorf("Ошибка при получении полей песни %d: %v", req.Id, err)
+		return 0, err
+	}
+
+	// Запрос без изменений не увеличивает версию и не записывает ревизию
+	if !req.DropUnchanged(current) {
+		if err = tx.Commit(); err != nil {
+			s.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+			return 0, err
+		}
+
+		return version, nil
+	}
+
 	updateSongQuery := "UPDATE songs SET "
 	updateParams := []interface{}{}
 	paramCount := 1
@@ -181,7 +280,7 @@ func (s SongsPostgres) UpdateSong(req dto.UpdateSongRequest) error {
 	}
 
 	if req.ReleaseDate != nil {
-		updateSongQuery += fmt.Sprintf("releaseDate = TO_DATE($%d, 'DD.MM.YYYY'), ", paramCount)
+		updateSongQuery += fmt.Sprintf("releaseDate = NULLIF($%d, '')::date, ", paramCount)
 		updateParams = append(updateParams, *req.ReleaseDate)
 		paramCount++
 	}
@@ -198,138 +297,260 @@ func (s SongsPostgres) UpdateSong(req dto.UpdateSongRequest) error {
 		paramCount++
 	}
 
+	// Поля, измененные клиентом, больше не считаются взятыми из внешнего API
+	var clientFields []string
+	if req.ReleaseDate != nil {
+		clientFields = append(clientFields, model.FieldReleaseDate)
+	}
+	if req.Text != nil {
+		clientFields = append(clientFields, model.FieldText)
+	}
+	if req.Link != nil {
+		clientFields = append(clientFields, model.FieldLink)
+	}
+
+	if len(clientFields) > 0 {
+		updateSongQuery += fmt.Sprintf("enriched_fields = ARRAY(SELECT f FROM unnest(enriched_fields) f WHERE f <> ALL($%d::text[])), ", paramCount)
+		updateSongQuery += fmt.Sprintf("enrichment_sources = enrichment_sources - $%d::text[], ", paramCount)
+		updateParams = append(updateParams, pq.Array(clientFields))
+		paramCount++
+	}
+
 	if len(updateParams) > 0 {
 		updateSongQuery = updateSongQuery[:len(updateSongQuery)-2]
 
 		updateSongQuery += fmt.Sprintf(" WHERE id = $%d", paramCount)
 		updateParams = append(updateParams, req.Id)
 
-		_, err = tx.Exec(updateSongQuery, updateParams...)
+		_, err = tx.ExecContext(ctx, updateSongQuery, updateParams...)
 		if err != nil {
 			s.logger.Errorf("Ошибка при обновлении песни: %v", err)
-			return err
+			return 0, translateError(err, "песня с id %d не найдена", req.Id)
+		}
+	}
+
+	// Текст, переданный целиком, заменяет все куплеты
+	if req.Text != nil {
+		if err = replaceVerses(ctx, tx, req.Id, *req.Text); err != nil {
+			s.logger.Errorf("Ошибка при сохранении куплетов песни %d: %v", req.Id, err)
+			return 0, err
 		}
 	}
 
 	if req.Group != nil {
 		var groupId int
 
-		err = tx.QueryRow("SELECT id FROM groups WHERE name = $1", *req.Group).Scan(&groupId)
-		if err == sql.ErrNoRows {
-			err = tx.QueryRow("INSERT INTO groups (name) VALUES ($1) RETURNING id", *req.Group).Scan(&groupId)
-			if err != nil {
-				s.logger.Errorf("Ошибка при создании новой группы: %v", err)
-				return err
-			}
-		} else if err != nil {
-			s.logger.Errorf("Ошибка при поиске группы: %v", err)
-			return err
+		if groupId, err = groupIdByName(ctx, tx, *req.Group); err != nil {
+			s.logger.Errorf("Ошибка при поиске или создании группы: %v", err)
+			return 0, translateError(err, "группа не создана")
 		}
 
-		_, err = tx.Exec("UPDATE songs SET group_id = $1 WHERE id = $2", groupId, req.Id)
+		_, err = tx.ExecContext(ctx, "UPDATE songs SET group_id = $1 WHERE id = $2", groupId, req.Id)
 		if err != nil {
 			s.logger.Errorf("Ошибка при обновлении привязки к группе: %v", err)
-			return err
+			return 0, err
 		}
 	}
 
+	if version, err = touchSong(ctx, tx, req.Id); err != nil {
+		s.logger.Errorf("Ошибка при обновлении версии песни %d: %v", req.Id, err)
+		return 0, err
+	}
+
+	if err = recordRevision(ctx, tx, req.Id); err != nil {
+		s.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", req.Id, err)
+		return 0, err
+	}
+
 	if err = tx.Commit(); err != nil {
 		s.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
-		return err
+		return 0, err
 	}
 
-	return nil
+	return version, nil
 }
 
-func (s SongsPostgres) DeleteSong(id int) error {
-	query := "DELETE FROM songs WHERE id = $1"
+// activeSongId подзапрос, который дает id песни $1, если она не в корзине, и NULL иначе.
+// Запросы к куплетам, переводам и другим данным песни сравнивают с ним song_id
+const activeSongId = "(SELECT id FROM songs WHERE id = $1 AND deleted_at IS NULL)"
 
-	result, err := s.db.Exec(query, id)
+// DeleteSong перемещает песню в корзину. Данные песни сохраняются до восстановления
+// или окончательного удаления фоновой очисткой. Версия растет, чтобы ETag песни в корзине
+// и после восстановления отличался от того, что видели клиенты до удаления
+func (s SongsPostgres) DeleteSong(ctx context.Context, id int, ifMatch dto.IfMatch) error {
+	tx, err := s.db.BeginTxx(ctx, nil)
 	if err != nil {
+		s.logger.Errorf("Ошибка при начале транзакции: %v", err)
 		return err
 	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
 
-	rowsAffected, err := result.RowsAffected()
+	version, err := lockSongVersion(ctx, tx, id)
 	if err != nil {
 		return err
 	}
 
-	if rowsAffected == 0 {
-		return fmt.Errorf("песня с id %d не найдена", id)
+	if err = ifMatch.Check(id, version); err != nil {
+		return err
 	}
 
-	return nil
-}
-
-
-func (s SongsPostgres) GetSongLyrics(req dto.GetSongLyricsRequest) ([]string, error) {
-	var lyrics string
-
-	query := "SELECT text FROM songs WHERE id = $1"
-
-	err := s.db.QueryRow(query, req.Id).Scan(&lyrics)
-	if err == sql.ErrNoRows {
-		return nil, errors.New("song not found")
-	} else if err != nil {
-		return nil, errors.New("failed to fetch song lyrics")
+	if _, err = tx.ExecContext(ctx, "UPDATE songs SET deleted_at = now(), version = version + 1 WHERE id = $1", id); err != nil {
+		s.logger.Errorf("Ошибка при удалении песни %d: %v", id, err)
+		return err
 	}
 
-	verses := strings.Split(lyrics, "\n\n")
-	start := (req.Offset - 1) * req.Limit
-	end := start + req.Limit
-	if start >= len(verses) {
-		return verses, nil
-	}
-	if end > len(verses) {
-		end = len(verses)
+	if err = tx.Commit(); err != nil {
+		s.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
 	}
 
-	return verses[start:end], nil
+	return nil
 }
 
-func (s SongsPostgres) GetSongById(id int) (model.Song, error) {
+
+func (s SongsPostgres) GetSongById(ctx context.Context, id int) (model.Song, error) {
 	var song model.Song
 
 	query := `
-		SELECT s.id, s.song, s.genre, TO_CHAR(s.releaseDate, 'DD.MM.YYYY') as releaseDate, s.text, s.link, 
-			   s.group_id, g.name as group_name
+		SELECT s.id, s.song, s.genre, COALESCE(TO_CHAR(s.releaseDate, 'DD.MM.YYYY'), '') as "releaseDate", s.text, s.link, 
+			   s.group_id, g.name as group_name, s.enrichment_status, s.enriched_fields, s.enrichment_sources,
+			   EXISTS (SELECT 1 FROM song_synced_lines l WHERE l.song_id = s.id) as has_synced_lyrics, s.version
 		FROM songs s
 		LEFT JOIN groups g ON s.group_id = g.id
-		WHERE s.id = $1
+		WHERE s.id = $1 AND s.deleted_at IS NULL
 	`
 
-	err := s.db.Get(&song, query, id)
+	err := s.db.GetContext(ctx, &song, query, id)
 	if err != nil {
 		s.logger.Errorf("Ошибка при получении песни по ID %d: %v", id, err)
-		return model.Song{}, err
+		return model.Song{}, translateError(err, "песня с id %d не найдена", id)
 	}
 
 	return song, nil
 }
 
-func (s SongsPostgres) SearchSongs(query string) ([]model.Song, error) {
-	var songs []model.Song
+const (
+	// headlineShortOptions подсвечивает короткие поля целиком
+	headlineShortOptions = "StartSel=<mark>, StopSel=</mark>, HighlightAll=true"
+	// headlineSnippetOptions вырезает из текста песни до двух фрагментов вокруг совпадений
+	headlineSnippetOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=20, MinWords=8, MaxFragments=2, FragmentDelimiter=\" ... \""
+)
+
+// SearchSongs выполняет полнотекстовый поиск по названию, группе, жанру и тексту.
+// Запрос разбирается через websearch_to_tsquery, результаты упорядочены по релевантности.
+// Для каждого совпавшего поля возвращается фрагмент с подсвеченными словами (ts_headline)
+func (s SongsPostgres) SearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error) {
+	songs := []model.SongSearchResult{}
+
+	orderBy, err := searchOrderBy(req.SortBy)
+	if err != nil {
+		return nil, err
+	}
+
+	sqlQuery := `
+		SELECT s.id, s.song, s.genre, COALESCE(TO_CHAR(s.releaseDate, 'DD.MM.YYYY'), '') as "releaseDate", s.text, s.link, 
+			   s.group_id, g.name as group_name, s.enrichment_status, s.enriched_fields, s.enrichment_sources,
+			   EXISTS (SELECT 1 FROM song_synced_lines l WHERE l.song_id = s.id) as has_synced_lyrics, s.version,
+			   ts_rank(s.search_vector, q.query) as rank,
+			   CASE WHEN to_tsvector('simple', coalesce(s.song, '')) @@ q.query
+					THEN ts_headline('simple', s.song, q.query, '` + headlineShortOptions + `') END as "highlights.song",
+			   CASE WHEN to_tsvector('simple', coalesce(g.name, '')) @@ q.query
+					THEN ts_headline('simple', g.name, q.query, '` + headlineShortOptions + `') END as "highlights.group",
+			   CASE WHEN to_tsvector('simple', coalesce(s.text, '')) @@ q.query
+					THEN ts_headline('simple', s.text, q.query, '` + headlineSnippetOptions + `') END as "highlights.text",
+			   CASE WHEN to_tsvector('simple', coalesce(s.genre, '')) @@ q.query
+					THEN ts_headline('simple', s.genre, q.query, '` + headlineShortOptions + `') END as "highlights.genre"
+		FROM songs s
+		LEFT JOIN groups g ON s.group_id = g.id,
+			websearch_to_tsquery('simple', $1) q(query)
+		WHERE s.search_vector @@ q.query AND s.deleted_at IS NULL
+		ORDER BY ` + orderBy
+
+	if err := s.db.SelectContext(ctx, &songs, sqlQuery, req.Query); err != nil {
+		s.logger.Errorf("Ошибка при поиске песен: %v", err)
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// FuzzySearchSongs ищет песни по триграммному сходству названия песни или группы,
+// что позволяет находить результаты при опечатках в запросе
+func (s SongsPostgres) FuzzySearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error) {
+	songs := []model.SongSearchResult{}
 
-	
-	searchParam := "%" + query + "%"
+	orderBy, err := searchOrderBy(req.SortBy)
+	if err != nil {
+		return nil, err
+	}
 
 	sqlQuery := `
-		SELECT s.id, s.song, s.genre, TO_CHAR(s.releaseDate, 'DD.MM.YYYY') as releaseDate, s.text, s.link, 
-			   s.group_id, g.name as group_name
+		SELECT s.id, s.song, s.genre, COALESCE(TO_CHAR(s.releaseDate, 'DD.MM.YYYY'), '') as "releaseDate", s.text, s.link,
+			   s.group_id, g.name as group_name, s.enrichment_status, s.enriched_fields, s.enrichment_sources,
+			   EXISTS (SELECT 1 FROM song_synced_lines l WHERE l.song_id = s.id) as has_synced_lyrics, s.version,
+			   GREATEST(word_similarity($1, s.song), COALESCE(word_similarity($1, g.name), 0)) as rank
 		FROM songs s
 		LEFT JOIN groups g ON s.group_id = g.id
-		WHERE 
-			s.song ILIKE $1 OR
-			g.name ILIKE $1 OR
-			s.text ILIKE $1 OR
-			s.genre ILIKE $1
-		ORDER BY s.id
+		WHERE ($1 <% s.song OR $1 <% g.name) AND s.deleted_at IS NULL
+		ORDER BY ` + orderBy + `
+		LIMIT $2
 	`
 
-	if err := s.db.Select(&songs, sqlQuery, searchParam); err != nil {
-		s.logger.Errorf("Ошибка при поиске песен: %v", err)
+	if err := s.db.SelectContext(ctx, &songs, sqlQuery, req.Query, req.Limit); err != nil {
+		s.logger.Errorf("Ошибка при нечетком поиске песен: %v", err)
 		return nil, err
 	}
 
 	return songs, nil
 }
+
+// searchOrderBy сортирует результаты поиска по заданным полям, а при равенстве — по релевантности
+func searchOrderBy(sortBy []dto.SortField) (string, error) {
+	if len(sortBy) == 0 {
+		return "rank DESC, s.id", nil
+	}
+
+	orderBy, err := songsOrderBy(sortBy)
+	if err != nil {
+		return "", err
+	}
+
+	return orderBy + ", rank DESC, s.id", nil
+}
+
+// SuggestSongs возвращает названия песен и групп для автодополнения.
+// Совпадения по префиксу идут первыми, затем похожие по триграммам
+func (s SongsPostgres) SuggestSongs(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error) {
+	suggestions := []model.Suggestion{}
+
+	sqlQuery := `
+		SELECT type, id, value, score
+		FROM (
+			SELECT 'song' as type, s.id, s.song as value, similarity(s.song, $1) as score
+			FROM songs s
+			WHERE (s.song ILIKE $2 OR s.song % $1) AND s.deleted_at IS NULL
+			UNION ALL
+			SELECT 'group' as type, g.id, g.name as value, similarity(g.name, $1) as score
+			FROM groups g
+			WHERE g.name ILIKE $2 OR g.name % $1
+		) suggestions
+		ORDER BY value ILIKE $2 DESC, score DESC, value
+		LIMIT $3
+	`
+
+	if err := s.db.SelectContext(ctx, &suggestions, sqlQuery, prefix, escapeLike(prefix)+"%", limit); err != nil {
+		s.logger.Errorf("Ошибка при получении подсказок: %v", err)
+		return nil, err
+	}
+
+	return suggestions, nil
+}
+
+// escapeLike экранирует спецсимволы шаблона LIKE
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
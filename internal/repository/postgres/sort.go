@@ -0,0 +1,82 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+)
+
+type sortColumn struct {
+	// expr выражение сортировки; NULL заменяется значением, чтобы сравнение по курсору было однозначным
+	expr string
+	// param приведение значения из курсора к типу выражения
+	param string
+}
+
+var songSortColumns = map[string]sortColumn{
+	"id":          {expr: "s.id", param: "$%d::int"},
+	"song":        {expr: "COALESCE(s.song, '')", param: "$%d"},
+	"group":       {expr: "COALESCE(g.name, '')", param: "$%d"},
+	"genre":       {expr: "COALESCE(s.genre, '')", param: "$%d"},
+	"releaseDate": {expr: "COALESCE(s.releaseDate, DATE '0001-01-01')", param: "COALESCE(TO_DATE(NULLIF($%d, ''), 'DD.MM.YYYY'), DATE '0001-01-01')"},
+}
+
+// songsOrderBy строит список выражений ORDER BY по полям сортировки
+func songsOrderBy(sortBy []dto.SortField) (string, error) {
+	parts := make([]string, 0, len(sortBy))
+
+	for _, field := range sortBy {
+		column, ok := songSortColumns[field.Field]
+		if !ok {
+			return "", fmt.Errorf("неизвестное поле сортировки: %s", field.Field)
+		}
+
+		if field.Desc {
+			parts = append(parts, column.expr+" DESC")
+		} else {
+			parts = append(parts, column.expr+" ASC")
+		}
+	}
+
+	return strings.Join(parts, ", "), nil
+}
+
+// songsKeysetCondition строит условие "строго после курсора" для сортировки с разными направлениями:
+// (k1 > v1) OR (k1 = v1 AND k2 < v2) OR ...
+// Значения курсора добавляются в params начиная с плейсхолдера paramCount
+func songsKeysetCondition(sortBy []dto.SortField, values []string, params []interface{}, paramCount int) (string, []interface{}, int, error) {
+	if len(values) != len(sortBy) {
+		return "", nil, 0, fmt.Errorf("курсор не соответствует сортировке")
+	}
+
+	placeholders := make([]string, len(sortBy))
+	for i, field := range sortBy {
+		column, ok := songSortColumns[field.Field]
+		if !ok {
+			return "", nil, 0, fmt.Errorf("неизвестное поле сортировки: %s", field.Field)
+		}
+
+		placeholders[i] = fmt.Sprintf(column.param, paramCount)
+		params = append(params, values[i])
+		paramCount++
+	}
+
+	var alternatives []string
+	for i, field := range sortBy {
+		var conditions []string
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, fmt.Sprintf("%s = %s", songSortColumns[sortBy[j].Field].expr, placeholders[j]))
+		}
+
+		operator := ">"
+		if field.Desc {
+			operator = "<"
+		}
+		conditions = append(conditions, fmt.Sprintf("%s %s %s", songSortColumns[field.Field].expr, operator, placeholders[i]))
+
+		alternatives = append(alternatives, "("+strings.Join(conditions, " AND ")+")")
+	}
+
+	return "(" + strings.Join(alternatives, " OR ") + ")", params, paramCount, nil
+}
@@ -0,0 +1,136 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type SyncedLyricsPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewSyncedLyricsPostgres(db *sqlx.DB) *SyncedLyricsPostgres {
+	return &SyncedLyricsPostgres{db: db, logger: logging.GetLogger()}
+}
+
+// GetSyncedLines возвращает строки синхронизированного текста по времени начала.
+// У песни без синхронизированного текста список пуст
+func (l SyncedLyricsPostgres) GetSyncedLines(ctx context.Context, songId int) ([]model.SyncedLine, error) {
+	lines := []model.SyncedLine{}
+
+	var exists bool
+	if err := l.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)", songId).Scan(&exists); err != nil {
+		l.logger.Errorf("Ошибка при проверке песни %d: %v", songId, err)
+		return nil, err
+	}
+	if !exists {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	query := "SELECT start_ms, text FROM song_synced_lines WHERE song_id = $1 ORDER BY position"
+
+	if err := l.db.SelectContext(ctx, &lines, query, songId); err != nil {
+		l.logger.Errorf("Ошибка при получении синхронизированного текста песни %d: %v", songId, err)
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+// SetSyncedLines заменяет синхронизированный текст песни строками lines в их порядке
+func (l SyncedLyricsPostgres) SetSyncedLines(ctx context.Context, songId int, lines []model.SyncedLine) error {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		l.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = lockSong(ctx, tx, songId); err != nil {
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx, "DELETE FROM song_synced_lines WHERE song_id = $1", songId); err != nil {
+		l.logger.Errorf("Ошибка при удалении синхронизированного текста песни %d: %v", songId, err)
+		return err
+	}
+
+	starts := make([]int64, len(lines))
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		starts[i], texts[i] = line.StartMs, line.Text
+	}
+
+	query := `
+		INSERT INTO song_synced_lines (song_id, position, start_ms, text)
+		SELECT $1, l.ord, l.start_ms, l.text
+		FROM unnest($2::int[], $3::text[]) WITH ORDINALITY AS l(start_ms, text, ord)
+	`
+
+	if _, err = tx.ExecContext(ctx, query, songId, pq.Array(starts), pq.Array(texts)); err != nil {
+		l.logger.Errorf("Ошибка при сохранении синхронизированного текста песни %d: %v", songId, err)
+		return err
+	}
+
+	// hasSyncedLyrics песни мог измениться
+	if _, err = touchSong(ctx, tx, songId); err != nil {
+		l.logger.Errorf("Ошибка при обновлении версии песни %d: %v", songId, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		l.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+func (l SyncedLyricsPostgres) DeleteSyncedLines(ctx context.Context, songId int) error {
+	tx, err := l.db.BeginTxx(ctx, nil)
+	if err != nil {
+		l.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = lockSong(ctx, tx, songId); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, "DELETE FROM song_synced_lines WHERE song_id = $1", songId)
+	if err != nil {
+		l.logger.Errorf("Ошибка при удалении синхронизированного текста песни %d: %v", songId, err)
+		return err
+	}
+
+	if err = expectAffected(result, "синхронизированный текст песни %d не найден", songId); err != nil {
+		return err
+	}
+
+	if _, err = touchSong(ctx, tx, songId); err != nil {
+		l.logger.Errorf("Ошибка при обновлении версии песни %d: %v", songId, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		l.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,90 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type TranslationsPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewTranslationsPostgres(db *sqlx.DB) *TranslationsPostgres {
+	return &TranslationsPostgres{db: db, logger: logging.GetLogger()}
+}
+
+// GetTranslations возвращает переводы песни по алфавиту языков
+func (t TranslationsPostgres) GetTranslations(ctx context.Context, songId int) ([]model.Translation, error) {
+	translations := []model.Translation{}
+
+	var exists bool
+	if err := t.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)", songId).Scan(&exists); err != nil {
+		t.logger.Errorf("Ошибка при проверке песни %d: %v", songId, err)
+		return nil, err
+	}
+	if !exists {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	query := "SELECT song_id, lang, text, updated_at FROM song_translations WHERE song_id = $1 ORDER BY lang"
+
+	if err := t.db.SelectContext(ctx, &translations, query, songId); err != nil {
+		t.logger.Errorf("Ошибка при получении переводов песни %d: %v", songId, err)
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (t TranslationsPostgres) GetTranslation(ctx context.Context, songId int, lang string) (model.Translation, error) {
+	var translation model.Translation
+
+	query := "SELECT song_id, lang, text, updated_at FROM song_translations WHERE song_id = " + activeSongId + " AND lang = $2"
+
+	if err := t.db.GetContext(ctx, &translation, query, songId, lang); err != nil {
+		t.logger.Errorf("Ошибка при получении перевода песни %d на %s: %v", songId, lang, err)
+		return model.Translation{}, translateError(err, "перевод песни %d на язык %s не найден", songId, lang)
+	}
+
+	return translation, nil
+}
+
+// SaveTranslation добавляет или заменяет перевод. Для несуществующей песни запрос не вставляет
+// строк. xmax = 0 только у строки, вставленной этим запросом, что отличает создание от замены
+func (t TranslationsPostgres) SaveTranslation(ctx context.Context, songId int, lang, text string) (model.Translation, bool, error) {
+	var result struct {
+		model.Translation
+		Created bool `db:"created"`
+	}
+
+	query := `
+		INSERT INTO song_translations (song_id, lang, text)
+		SELECT id, $2, $3 FROM songs WHERE id = $1 AND deleted_at IS NULL
+		ON CONFLICT (song_id, lang) DO UPDATE SET text = EXCLUDED.text, updated_at = now()
+		RETURNING song_id, lang, text, updated_at, (xmax = 0) as created
+	`
+
+	if err := t.db.GetContext(ctx, &result, query, songId, lang, text); err != nil {
+		t.logger.Errorf("Ошибка при сохранении перевода песни %d на %s: %v", songId, lang, err)
+		return model.Translation{}, false, translateError(err, "песня с id %d не найдена", songId)
+	}
+
+	return result.Translation, result.Created, nil
+}
+
+func (t TranslationsPostgres) DeleteTranslation(ctx context.Context, songId int, lang string) error {
+	query := "DELETE FROM song_translations WHERE song_id = " + activeSongId + " AND lang = $2"
+
+	result, err := t.db.ExecContext(ctx, query, songId, lang)
+	if err != nil {
+		t.logger.Errorf("Ошибка при удалении перевода песни %d на %s: %v", songId, lang, err)
+		return err
+	}
+
+	return expectAffected(result, "перевод песни %d на язык %s не найден", songId, lang)
+}
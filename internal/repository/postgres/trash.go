@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type TrashPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewTrashPostgres(db *sqlx.DB) *TrashPostgres {
+	return &TrashPostgres{db: db, logger: logging.GetLogger()}
+}
+
+// GetDeletedSongs возвращает песни в корзине, начиная с удаленных последними
+func (t TrashPostgres) GetDeletedSongs(ctx context.Context) ([]model.Song, error) {
+	songs := []model.Song{}
+
+	query := `
+		SELECT s.id, s.song, s.genre, COALESCE(TO_CHAR(s.releaseDate, 'DD.MM.YYYY'), '') as "releaseDate", s.text, s.link,
+			   s.group_id, g.name as group_name, s.enrichment_status, s.enriched_fields, s.enrichment_sources,
+			   EXISTS (SELECT 1 FROM song_synced_lines l WHERE l.song_id = s.id) as has_synced_lyrics, s.version, s.deleted_at
+		FROM songs s
+		LEFT JOIN groups g ON s.group_id = g.id
+		WHERE s.deleted_at IS NOT NULL
+		ORDER BY s.deleted_at DESC, s.id
+	`
+
+	if err := t.db.SelectContext(ctx, &songs, query); err != nil {
+		t.logger.Errorf("Ошибка при получении песен из корзины: %v", err)
+		return nil, err
+	}
+
+	return songs, nil
+}
+
+// RestoreSong возвращает песню из корзины вместе со всеми ее данными и новой версией
+func (t TrashPostgres) RestoreSong(ctx context.Context, id int) error {
+	result, err := t.db.ExecContext(ctx, "UPDATE songs SET deleted_at = NULL, version = version + 1 WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		t.logger.Errorf("Ошибка при восстановлении песни %d: %v", id, err)
+		return err
+	}
+
+	return expectAffected(result, "песня с id %d не найдена в корзине", id)
+}
+
+// PurgeDeletedSongs окончательно удаляет песни, перемещенные в корзину раньше deletedBefore.
+// Куплеты, переводы и ревизии удаляются каскадно
+func (t TrashPostgres) PurgeDeletedSongs(ctx context.Context, deletedBefore time.Time) (int, error) {
+	result, err := t.db.ExecContext(ctx, "DELETE FROM songs WHERE deleted_at < $1", deletedBefore)
+	if err != nil {
+		t.logger.Errorf("Ошибка при очистке корзины: %v", err)
+		return 0, err
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(purged), nil
+}
@@ -0,0 +1,370 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+type VersesPostgres struct {
+	db     *sqlx.DB
+	logger *logging.Logger
+}
+
+func NewVersesPostgres(db *sqlx.DB) *VersesPostgres {
+	return &VersesPostgres{db: db, logger: logging.GetLogger()}
+}
+
+func (v VersesPostgres) GetSongVerses(ctx context.Context, songId int) ([]model.Verse, error) {
+	verses := []model.Verse{}
+
+	var exists bool
+	if err := v.db.QueryRowContext(ctx, "SELECT EXISTS (SELECT 1 FROM songs WHERE id = $1 AND deleted_at IS NULL)", songId).Scan(&exists); err != nil {
+		v.logger.Errorf("Ошибка при проверке песни %d: %v", songId, err)
+		return nil, err
+	}
+	if !exists {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	query := "SELECT id, song_id, position, type, text FROM song_verses WHERE song_id = $1 ORDER BY position"
+
+	if err := v.db.SelectContext(ctx, &verses, query, songId); err != nil {
+		v.logger.Errorf("Ошибка при получении куплетов песни %d: %v", songId, err)
+		return nil, err
+	}
+
+	return verses, nil
+}
+
+func (v VersesPostgres) GetVerse(ctx context.Context, songId, verseId int) (model.Verse, error) {
+	var verse model.Verse
+
+	query := "SELECT id, song_id, position, type, text FROM song_verses WHERE song_id = " + activeSongId + " AND id = $2"
+
+	if err := v.db.GetContext(ctx, &verse, query, songId, verseId); err != nil {
+		v.logger.Errorf("Ошибка при получении куплета %d песни %d: %v", verseId, songId, err)
+		return model.Verse{}, translateError(err, "куплет %d песни %d не найден", verseId, songId)
+	}
+
+	return verse, nil
+}
+
+// AddVerse вставляет куплет на позицию req.Position, сдвигая последующие, или в конец песни
+func (v VersesPostgres) AddVerse(ctx context.Context, req dto.AddVerseRequest) (model.Verse, error) {
+	var verse model.Verse
+
+	tx, err := v.db.BeginTxx(ctx, nil)
+	if err != nil {
+		v.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return model.Verse{}, err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = lockSong(ctx, tx, req.SongId); err != nil {
+		return model.Verse{}, err
+	}
+
+	var count int
+	if err = tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM song_verses WHERE song_id = $1", req.SongId).Scan(&count); err != nil {
+		v.logger.Errorf("Ошибка при подсчете куплетов песни %d: %v", req.SongId, err)
+		return model.Verse{}, err
+	}
+
+	position := req.Position
+	if position == 0 {
+		position = count + 1
+	}
+	if position > count+1 {
+		err = errs.Validation("позиция куплета должна быть от 1 до %d, получено %d", count+1, position)
+		return model.Verse{}, err
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE song_verses SET position = position + 1 WHERE song_id = $1 AND position >= $2", req.SongId, position)
+	if err != nil {
+		v.logger.Errorf("Ошибка при сдвиге куплетов песни %d: %v", req.SongId, err)
+		return model.Verse{}, err
+	}
+
+	query := `
+		INSERT INTO song_verses (song_id, position, type, text)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, song_id, position, type, text
+	`
+
+	if err = tx.GetContext(ctx, &verse, query, req.SongId, position, req.Type, req.Text); err != nil {
+		v.logger.Errorf("Ошибка при добавлении куплета песни %d: %v", req.SongId, err)
+		return model.Verse{}, translateError(err, "куплет не добавлен")
+	}
+
+	if err = refreshSongText(ctx, tx, req.SongId); err != nil {
+		return model.Verse{}, err
+	}
+
+	if err = recordRevision(ctx, tx, req.SongId); err != nil {
+		v.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", req.SongId, err)
+		return model.Verse{}, err
+	}
+
+	if err = tx.Commit(); err != nil {
+		v.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return model.Verse{}, err
+	}
+
+	return verse, nil
+}
+
+// UpdateVerse меняет тип и текст куплета. Правка текста пересобирает текст песни
+// и сохраняет ревизию, правка одного типа только повышает версию песни
+func (v VersesPostgres) UpdateVerse(ctx context.Context, req dto.UpdateVerseRequest) error {
+	tx, err := v.db.BeginTxx(ctx, nil)
+	if err != nil {
+		v.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = lockSong(ctx, tx, req.SongId); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE song_verses
+		SET type = COALESCE($3, type), text = COALESCE($4, text)
+		WHERE song_id = $1 AND id = $2
+	`
+
+	result, err := tx.ExecContext(ctx, query, req.SongId, req.VerseId, req.Type, req.Text)
+	if err != nil {
+		v.logger.Errorf("Ошибка при изменении куплета %d песни %d: %v", req.VerseId, req.SongId, err)
+		return translateError(err, "куплет %d песни %d не найден", req.VerseId, req.SongId)
+	}
+
+	if err = expectAffected(result, "куплет %d песни %d не найден", req.VerseId, req.SongId); err != nil {
+		return err
+	}
+
+	// Тип куплета не входит в ревизию: без правки текста снимок не изменился бы,
+	// поэтому только растет версия песни
+	if req.Text == nil {
+		if _, err = touchSong(ctx, tx, req.SongId); err != nil {
+			v.logger.Errorf("Ошибка при обновлении версии песни %d: %v", req.SongId, err)
+			return err
+		}
+	} else {
+		if err = refreshSongText(ctx, tx, req.SongId); err != nil {
+			return err
+		}
+
+		if err = recordRevision(ctx, tx, req.SongId); err != nil {
+			v.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", req.SongId, err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		v.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteVerse удаляет куплет и сдвигает последующие, чтобы позиции шли подряд
+func (v VersesPostgres) DeleteVerse(ctx context.Context, songId, verseId int) error {
+	tx, err := v.db.BeginTxx(ctx, nil)
+	if err != nil {
+		v.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = lockSong(ctx, tx, songId); err != nil {
+		return err
+	}
+
+	var position int
+	err = tx.QueryRowContext(ctx, "DELETE FROM song_verses WHERE song_id = $1 AND id = $2 RETURNING position", songId, verseId).Scan(&position)
+	if err != nil {
+		v.logger.Errorf("Ошибка при удалении куплета %d песни %d: %v", verseId, songId, err)
+		return translateError(err, "куплет %d песни %d не найден", verseId, songId)
+	}
+
+	_, err = tx.ExecContext(ctx, "UPDATE song_verses SET position = position - 1 WHERE song_id = $1 AND position > $2", songId, position)
+	if err != nil {
+		v.logger.Errorf("Ошибка при сдвиге куплетов песни %d: %v", songId, err)
+		return err
+	}
+
+	if err = refreshSongText(ctx, tx, songId); err != nil {
+		return err
+	}
+
+	if err = recordRevision(ctx, tx, songId); err != nil {
+		v.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", songId, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		v.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// ReorderVerses расставляет куплеты в порядке req.VerseIds, который должен
+// содержать каждый куплет песни ровно один раз
+func (v VersesPostgres) ReorderVerses(ctx context.Context, req dto.ReorderVersesRequest) error {
+	tx, err := v.db.BeginTxx(ctx, nil)
+	if err != nil {
+		v.logger.Errorf("Ошибка при начале транзакции: %v", err)
+		return err
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = lockSong(ctx, tx, req.SongId); err != nil {
+		return err
+	}
+
+	var verseIds []int
+	if err = tx.SelectContext(ctx, &verseIds, "SELECT id FROM song_verses WHERE song_id = $1", req.SongId); err != nil {
+		v.logger.Errorf("Ошибка при получении куплетов песни %d: %v", req.SongId, err)
+		return err
+	}
+
+	if err = checkVerseOrder(verseIds, req.VerseIds); err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE song_verses v
+		SET position = o.ord
+		FROM unnest($2::int[]) WITH ORDINALITY AS o(id, ord)
+		WHERE v.song_id = $1 AND v.id = o.id
+	`
+
+	if _, err = tx.ExecContext(ctx, query, req.SongId, pq.Array(req.VerseIds)); err != nil {
+		v.logger.Errorf("Ошибка при изменении порядка куплетов песни %d: %v", req.SongId, err)
+		return err
+	}
+
+	if err = refreshSongText(ctx, tx, req.SongId); err != nil {
+		return err
+	}
+
+	if err = recordRevision(ctx, tx, req.SongId); err != nil {
+		v.logger.Errorf("Ошибка при сохранении ревизии песни %d: %v", req.SongId, err)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		v.logger.Errorf("Ошибка при фиксации транзакции: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// checkVerseOrder проверяет, что order содержит каждый из verseIds ровно один раз
+func checkVerseOrder(verseIds, order []int) error {
+	if len(order) != len(verseIds) {
+		return errs.Validation("нужно указать все куплеты песни (%d), указано %d", len(verseIds), len(order))
+	}
+
+	pending := make(map[int]bool, len(verseIds))
+	for _, id := range verseIds {
+		pending[id] = true
+	}
+
+	for _, id := range order {
+		if !pending[id] {
+			return errs.Validation("куплет %d не принадлежит песне или указан дважды", id)
+		}
+		delete(pending, id)
+	}
+
+	return nil
+}
+
+// lockSong блокирует строку песни до конца транзакции, чтобы правки куплетов одной песни
+// выполнялись по очереди и позиции не расходились. Песня в корзине считается ненайденной
+func lockSong(ctx context.Context, tx *sqlx.Tx, songId int) error {
+	_, err := lockSongVersion(ctx, tx, songId)
+	return err
+}
+
+// lockSongVersion блокирует строку песни, как lockSong, и возвращает ее версию
+func lockSongVersion(ctx context.Context, tx *sqlx.Tx, songId int) (int, error) {
+	var version int
+
+	err := tx.QueryRowContext(ctx, "SELECT version FROM songs WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", songId).Scan(&version)
+	if err == sql.ErrNoRows {
+		return 0, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	return version, err
+}
+
+// touchSong увеличивает версию песни после изменения, которое видно в ее представлении
+func touchSong(ctx context.Context, tx *sqlx.Tx, songId int) (int, error) {
+	var version int
+
+	err := tx.QueryRowContext(ctx, "UPDATE songs SET version = version + 1 WHERE id = $1 RETURNING version", songId).Scan(&version)
+	return version, err
+}
+
+// refreshSongText пересобирает songs.text из куплетов. Текст, измененный по куплетам,
+// больше не считается взятым из внешнего API
+func refreshSongText(ctx context.Context, tx *sqlx.Tx, songId int) error {
+	query := `
+		UPDATE songs
+		SET text = COALESCE((SELECT string_agg(text, E'\n\n' ORDER BY position) FROM song_verses WHERE song_id = $1), ''),
+			enriched_fields = array_remove(enriched_fields, 'text'),
+			enrichment_sources = enrichment_sources - 'text',
+			version = version + 1
+		WHERE id = $1
+	`
+
+	_, err := tx.ExecContext(ctx, query, songId)
+	return err
+}
+
+// replaceVerses заменяет куплеты песни куплетами text. Вызывается при записи текста
+// целиком, поэтому все куплеты получают тип verse
+func replaceVerses(ctx context.Context, tx *sqlx.Tx, songId int, text string) error {
+	if _, err := tx.ExecContext(ctx, "DELETE FROM song_verses WHERE song_id = $1", songId); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO song_verses (song_id, position, text)
+		SELECT $1, v.ord, v.text
+		FROM unnest($2::text[]) WITH ORDINALITY AS v(text, ord)
+	`
+
+	_, err := tx.ExecContext(ctx, query, songId, pq.Array(dto.SplitVerses(text)))
+	return err
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"time"
+
 	"github.com/jmoiron/sqlx"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
@@ -8,21 +11,87 @@ import (
 )
 
 type Songs interface {
-	GetSongs(resp dto.GetSongsRequest) ([]model.Song, error)
-	AddSong(song dto.AddSongRequest) (int, error)
-	UpdateSong(song dto.UpdateSongRequest) error
-	DeleteSong(id int) error
-	GetSongLyrics(req dto.GetSongLyricsRequest) ([]string, error)
-	GetSongById(id int) (model.Song, error)
-	SearchSongs(query string) ([]model.Song, error)
+	GetSongs(ctx context.Context, resp dto.GetSongsRequest) ([]model.Song, error)
+	AddSong(ctx context.Context, song dto.AddSongRequest, enrichmentStatus string) (int, error)
+	UpdateSong(ctx context.Context, song dto.UpdateSongRequest) (int, error)
+	DeleteSong(ctx context.Context, id int, ifMatch dto.IfMatch) error
+	GetSongById(ctx context.Context, id int) (model.Song, error)
+	SearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error)
+	FuzzySearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error)
+	SuggestSongs(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error)
+}
+
+type Groups interface {
+	GetGroups(ctx context.Context) ([]model.Group, error)
+	GetGroupById(ctx context.Context, id int) (model.Group, error)
+	AddGroup(ctx context.Context, group dto.AddGroupRequest) (int, error)
+	UpdateGroup(ctx context.Context, group dto.UpdateGroupRequest) error
+	DeleteGroup(ctx context.Context, id int) error
+	MergeGroups(ctx context.Context, req dto.MergeGroupsRequest) (dto.MergeGroupsResult, error)
+}
+
+type Verses interface {
+	GetSongVerses(ctx context.Context, songId int) ([]model.Verse, error)
+	GetVerse(ctx context.Context, songId, verseId int) (model.Verse, error)
+	AddVerse(ctx context.Context, req dto.AddVerseRequest) (model.Verse, error)
+	UpdateVerse(ctx context.Context, req dto.UpdateVerseRequest) error
+	DeleteVerse(ctx context.Context, songId, verseId int) error
+	ReorderVerses(ctx context.Context, req dto.ReorderVersesRequest) error
+}
+
+type SyncedLyrics interface {
+	GetSyncedLines(ctx context.Context, songId int) ([]model.SyncedLine, error)
+	SetSyncedLines(ctx context.Context, songId int, lines []model.SyncedLine) error
+	DeleteSyncedLines(ctx context.Context, songId int) error
+}
+
+type Translations interface {
+	GetTranslations(ctx context.Context, songId int) ([]model.Translation, error)
+	GetTranslation(ctx context.Context, songId int, lang string) (model.Translation, error)
+	// SaveTranslation добавляет или заменяет перевод и сообщает, был ли он создан
+	SaveTranslation(ctx context.Context, songId int, lang, text string) (model.Translation, bool, error)
+	DeleteTranslation(ctx context.Context, songId int, lang string) error
+}
+
+type Revisions interface {
+	GetRevisions(ctx context.Context, songId int) ([]model.SongRevision, error)
+	GetRevision(ctx context.Context, songId, revision int) (model.SongRevision, error)
+}
+
+type Trash interface {
+	GetDeletedSongs(ctx context.Context) ([]model.Song, error)
+	RestoreSong(ctx context.Context, id int) error
+	// PurgeDeletedSongs окончательно удаляет песни, перемещенные в корзину раньше deletedBefore
+	PurgeDeletedSongs(ctx context.Context, deletedBefore time.Time) (int, error)
+}
+
+type Enrichment interface {
+	ClaimSongsForEnrichment(ctx context.Context, limit int, lease time.Duration) ([]model.EnrichmentTask, error)
+	CompleteEnrichment(ctx context.Context, id, version int, details dto.SongDetails, fields []string) error
+	FailEnrichment(ctx context.Context, id int, reason string, retryAt *time.Time) error
+	RequestEnrichment(ctx context.Context, id int, policy string) error
 }
 
 type Repository struct {
 	Songs
+	Groups
+	Verses
+	SyncedLyrics
+	Translations
+	Revisions
+	Trash
+	Enrichment
 }
 
 func NewRepository(db *sqlx.DB) *Repository {
 	return &Repository{
-		Songs: postgres.NewSongsPostgres(db),
+		Songs:        postgres.NewSongsPostgres(db),
+		Groups:       postgres.NewGroupsPostgres(db),
+		Verses:       postgres.NewVersesPostgres(db),
+		SyncedLyrics: postgres.NewSyncedLyricsPostgres(db),
+		Translations: postgres.NewTranslationsPostgres(db),
+		Revisions:    postgres.NewRevisionsPostgres(db),
+		Trash:        postgres.NewTrashPostgres(db),
+		Enrichment:   postgres.NewEnrichmentPostgres(db),
 	}
 }
@@ -1,6 +1,10 @@
 package router_v1
 
 import (
+	"expvar"
+	"os"
+	"time"
+
 	"github.com/gin-gonic/gin"
 
 	swaggerFiles "github.com/swaggo/files"
@@ -10,24 +14,92 @@ import (
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/handler"
 )
 
+const defaultRequestTimeout = 10 * time.Second
+
+// requestTimeout читает ограничение времени обработки запроса из REQUEST_TIMEOUT (например, 15s)
+func requestTimeout() time.Duration {
+	if timeout, err := time.ParseDuration(os.Getenv("REQUEST_TIMEOUT")); err == nil && timeout > 0 {
+		return timeout
+	}
+	return defaultRequestTimeout
+}
 
 func NewRouter(h *handler.Handler) *gin.Engine {
 	router := gin.New()
 
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/debug/vars", gin.WrapH(expvar.Handler()))
+
+	api := router.Group("/api/v1", handler.RequestTimeout(requestTimeout()), handler.ChangeAuthor())
 
-	songs := router.Group("/api/v1")
+	songs := api.Group("")
 	{
 		songs.GET("/songs", h.GetSongs)
 		songs.GET("/song/:id", h.GetSongById)
 		songs.GET("/song/:id/lyrics", h.GetSongLyrics)
+		songs.GET("/song/:id/lyrics/synced", h.GetSyncedLyrics)
 		songs.GET("/songs/search/:query", h.SearchSongs)
+		songs.GET("/songs/suggest", h.SuggestSongs)
 
 		songs.DELETE("/song/:id", h.DeleteSong)
+		songs.DELETE("/song/:id/lyrics/synced", h.DeleteSyncedLyrics)
 
 		songs.PUT("/song", h.UpdateSong)
+		songs.PUT("/song/:id/lyrics/synced", h.ImportSyncedLyrics)
 		songs.POST("/song", h.AddSong)
+		songs.POST("/song/:id/enrich", h.EnrichSong)
+	}
+
+	verses := api.Group("/song/:id/verses")
+	{
+		verses.GET("", h.GetSongVerses)
+		verses.GET("/:verseId", h.GetVerse)
+
+		verses.DELETE("/:verseId", h.DeleteVerse)
+
+		verses.PUT("/order", h.ReorderVerses)
+		verses.PUT("/:verseId", h.UpdateVerse)
+		verses.POST("", h.AddVerse)
+	}
+
+	translations := api.Group("/song/:id/translations")
+	{
+		translations.GET("", h.GetTranslations)
+		translations.GET("/:lang", h.GetTranslation)
+
+		translations.DELETE("/:lang", h.DeleteTranslation)
+
+		translations.PUT("/:lang", h.SaveTranslation)
+	}
+
+	trash := api.Group("/songs/trash")
+	{
+		trash.GET("", h.GetTrash)
+
+		trash.POST("/:id/restore", h.RestoreSong)
+	}
+
+	revisions := api.Group("/song/:id/revisions")
+	{
+		revisions.GET("", h.GetRevisions)
+		revisions.GET("/diff", h.DiffRevisions)
+		revisions.GET("/:revision", h.GetRevision)
+
+		revisions.POST("/:revision/restore", h.RestoreRevision)
+	}
+
+	groups := api.Group("/groups")
+	{
+		groups.GET("", h.GetGroups)
+		groups.GET("/:id", h.GetGroupById)
+		groups.GET("/:id/songs", h.GetGroupSongs)
+
+		groups.DELETE("/:id", h.DeleteGroup)
+
+		groups.PUT("/:id", h.UpdateGroup)
+		groups.POST("", h.AddGroup)
+		groups.POST("/merge", h.MergeGroups)
 	}
 
 	return router
-}
\ No newline at end of file
+}
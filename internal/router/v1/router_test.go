@@ -0,0 +1,1809 @@
+package router_v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/handler"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	router_v1 "github.com/pelicanch1k/EffectiveMobileTestTask/internal/router/v1"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/fakeinfo"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/linediff"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/memrepo"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitNop()
+	gin.SetMode(gin.TestMode)
+
+	os.Exit(m.Run())
+}
+
+// testEnv сервис поверх репозитория в памяти и fakeinfo.API с тестовыми данными:
+//
+//	1 Muse — Supermassive Black Hole (rock, 2006-06-19), три куплета
+//	2 Muse — Hysteria (rock, 2003-12-01), три куплета и синхронизированный текст hysteriaLRC
+//	3 Queen — Bohemian Rhapsody (rock, 1975-10-31), два куплета и перевод de из одного куплета
+//	4 Кино — Группа крови (post-punk, 1988-01-01), два куплета и перевод en из двух куплетов
+//	5 Queen — Radio Ga Ga (pop, 1984-01-23), без текста и ссылки, ожидает дополнения
+//
+// Группы: 1 Muse, 2 Queen, 3 Кино и 4 Radiohead без песен
+// hysteriaLRC синхронизированный текст песни 2: три строки и пауза в конце
+const hysteriaLRC = "[ti:Hysteria]\n[ar:Muse]\n[00:10.50]It's bugging me\n[00:14.00]Grating me\n[00:17.25]And twisting me around\n[00:21.00]\n"
+
+type testEnv struct {
+	router   *gin.Engine
+	services *service.Service
+	api      *fakeinfo.API
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	api := fakeinfo.NewAPI()
+	services := service.NewService(memrepo.NewRepository(), api)
+	env := &testEnv{
+		router:   router_v1.NewRouter(handler.NewHandler(services, logging.GetLogger())),
+		services: services,
+		api:      api,
+	}
+
+	ctx := context.Background()
+	songs := []dto.AddSongRequest{
+		{
+			Group: "Muse", Song: "Supermassive Black Hole", Genre: "rock", ReleaseDate: "2006-06-19",
+			Text: "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?\n\n" +
+				"You caught me under false pretenses\nHow long before you let me go?\n\n" +
+				"You set my soul alight",
+			Link: "https://www.youtube.com/watch?v=Xsp3_a-PMTw", Enrich: model.EnrichNever,
+		},
+		{
+			Group: "Muse", Song: "Hysteria", Genre: "rock", ReleaseDate: "2003-12-01",
+			Text: "It's bugging me\n\nGrating me\n\nAnd twisting me around",
+			Link: "https://www.youtube.com/watch?v=3dm_5qWWDV8", Enrich: model.EnrichNever,
+		},
+		{
+			Group: "Queen", Song: "Bohemian Rhapsody", Genre: "rock", ReleaseDate: "31.10.1975",
+			Text: "Is this the real life?\n\nIs this just fantasy?",
+			Link: "https://www.youtube.com/watch?v=fJ9rUzIMcZQ", Enrich: model.EnrichNever,
+		},
+		{
+			Group: "Кино", Song: "Группа крови", Genre: "post-punk", ReleaseDate: "1988-01-01",
+			Text: "Теплое место, но улицы ждут\n\nОтпечатков наших ног",
+			Link: "https://www.youtube.com/watch?v=ZqqaLWXW4Pc", Enrich: model.EnrichNever,
+		},
+		{Group: "Queen", Song: "Radio Ga Ga", Genre: "pop", ReleaseDate: "1984-01-23"},
+	}
+	for _, song := range songs {
+		if _, err := services.AddSong(ctx, song); err != nil {
+			t.Fatalf("AddSong(%s): %v", song.Song, err)
+		}
+	}
+
+	if _, err := services.ImportLRC(ctx, 2, hysteriaLRC); err != nil {
+		t.Fatalf("ImportLRC: %v", err)
+	}
+
+	translations := []dto.SaveTranslationRequest{
+		{SongId: 3, Lang: "de", Text: "Ist das das wahre Leben? Ist das nur Fantasie?"},
+		{SongId: 4, Lang: "en", Text: "A warm place, but the streets are waiting\n\nFor the prints of our feet"},
+	}
+	for _, translation := range translations {
+		if _, _, err := services.SaveTranslation(ctx, translation); err != nil {
+			t.Fatalf("SaveTranslation(%d, %s): %v", translation.SongId, translation.Lang, err)
+		}
+	}
+
+	if _, err := services.AddGroup(ctx, dto.AddGroupRequest{Name: "Radiohead"}); err != nil {
+		t.Fatalf("AddGroup: %v", err)
+	}
+
+	return env
+}
+
+func (e *testEnv) do(t *testing.T, method, target, body string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, values := range header {
+		for _, value := range values {
+			req.Header.Add(name, value)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	e.router.ServeHTTP(rec, req)
+
+	return rec
+}
+
+type routeTest struct {
+	name       string
+	method     string
+	target     string
+	body       string
+	header     http.Header
+	wantStatus int
+	// check дополнительные проверки успешного ответа
+	check func(t *testing.T, rec *httptest.ResponseRecorder)
+}
+
+func runRouteTests(t *testing.T, tests []routeTest) {
+	t.Helper()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+
+			rec := env.do(t, tt.method, tt.target, tt.body, tt.header)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("%s %s: status %d, want %d; body: %s", tt.method, tt.target, rec.Code, tt.wantStatus, rec.Body)
+			}
+
+			if tt.wantStatus >= http.StatusBadRequest {
+				assertProblem(t, rec, tt.wantStatus)
+			}
+			if tt.check != nil {
+				tt.check(t, rec)
+			}
+		})
+	}
+}
+
+// assertProblem проверяет, что ошибка отдана в формате RFC 7807
+func assertProblem(t *testing.T, rec *httptest.ResponseRecorder, status int) {
+	t.Helper()
+
+	if contentType := rec.Header().Get("Content-Type"); !strings.HasPrefix(contentType, "application/problem+json") {
+		t.Errorf("Content-Type = %q, want application/problem+json", contentType)
+	}
+
+	var problem struct {
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail"`
+	}
+	decode(t, rec, &problem)
+
+	if problem.Status != status || problem.Title != http.StatusText(status) {
+		t.Errorf("problem = %+v, want status %d", problem, status)
+	}
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder, v interface{}) {
+	t.Helper()
+
+	if err := json.Unmarshal(rec.Body.Bytes(), v); err != nil {
+		t.Fatalf("decode %s: %v", rec.Body, err)
+	}
+}
+
+// wantSongIDs проверяет идентификаторы и порядок песен в ответе-массиве
+func wantSongIDs(ids ...int) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var songs []model.Song
+		decode(t, rec, &songs)
+		assertIDs(t, songIDs(songs), ids)
+	}
+}
+
+func songIDs(songs []model.Song) []int {
+	ids := []int{}
+	for _, song := range songs {
+		ids = append(ids, song.ID)
+	}
+	return ids
+}
+
+func assertIDs(t *testing.T, got, want []int) {
+	t.Helper()
+
+	if want == nil {
+		want = []int{}
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("ids = %v, want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("ids = %v, want %v", got, want)
+		}
+	}
+}
+
+// wantLyrics проверяет страницу куплетов в ответе GET /song/:id/lyrics
+func wantLyrics(page, total int, hasMore bool, verses ...string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var lyrics dto.SongLyrics
+		decode(t, rec, &lyrics)
+
+		if lyrics.Page != page || lyrics.TotalVerses != total || lyrics.HasMore != hasMore {
+			t.Fatalf("page = %d, total_verses = %d, has_more = %v; want %d, %d, %v",
+				lyrics.Page, lyrics.TotalVerses, lyrics.HasMore, page, total, hasMore)
+		}
+		if lyrics.Verses == nil || strings.Join(lyrics.Verses, "|") != strings.Join(verses, "|") {
+			t.Fatalf("verses = %q, want %q", lyrics.Verses, verses)
+		}
+	}
+}
+
+func wantHeader(name, value string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		if got := rec.Header().Get(name); got != value {
+			t.Fatalf("header %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func checkAll(checks ...func(t *testing.T, rec *httptest.ResponseRecorder)) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		for _, check := range checks {
+			check(t, rec)
+		}
+	}
+}
+
+func TestGetSongs(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "all songs", method: http.MethodGet, target: "/api/v1/songs", wantStatus: http.StatusOK, check: wantSongIDs(1, 2, 3, 4, 5)},
+		{name: "by id", method: http.MethodGet, target: "/api/v1/songs?id=3", wantStatus: http.StatusOK, check: wantSongIDs(3)},
+		{name: "by group name case insensitive", method: http.MethodGet, target: "/api/v1/songs?group=muse", wantStatus: http.StatusOK, check: wantSongIDs(1, 2)},
+		{name: "by group id", method: http.MethodGet, target: "/api/v1/songs?groupId=2", wantStatus: http.StatusOK, check: wantSongIDs(3, 5)},
+		{name: "by genre", method: http.MethodGet, target: "/api/v1/songs?genre=punk", wantStatus: http.StatusOK, check: wantSongIDs(4)},
+		{name: "by song", method: http.MethodGet, target: "/api/v1/songs?song=hyst", wantStatus: http.StatusOK, check: wantSongIDs(2)},
+		{name: "by text", method: http.MethodGet, target: "/api/v1/songs?text=fantasy", wantStatus: http.StatusOK, check: wantSongIDs(3)},
+		{name: "by link", method: http.MethodGet, target: "/api/v1/songs?link=3dm_5qWWDV8", wantStatus: http.StatusOK, check: wantSongIDs(2)},
+		{name: "by exact release date", method: http.MethodGet, target: "/api/v1/songs?releaseDate=31.10.1975", wantStatus: http.StatusOK, check: wantSongIDs(3)},
+		{name: "released after", method: http.MethodGet, target: "/api/v1/songs?releasedAfter=2000-01-01", wantStatus: http.StatusOK, check: wantSongIDs(1, 2)},
+		{name: "released before", method: http.MethodGet, target: "/api/v1/songs?releasedBefore=01.01.1985", wantStatus: http.StatusOK, check: wantSongIDs(3, 5)},
+		{name: "by year", method: http.MethodGet, target: "/api/v1/songs?year=1988", wantStatus: http.StatusOK, check: wantSongIDs(4)},
+		{name: "by decade", method: http.MethodGet, target: "/api/v1/songs?decade=80s", wantStatus: http.StatusOK, check: wantSongIDs(4, 5)},
+		{name: "by enrichment status", method: http.MethodGet, target: "/api/v1/songs?enrichmentStatus=pending", wantStatus: http.StatusOK, check: wantSongIDs(5)},
+		{name: "by skipped enrichment status", method: http.MethodGet, target: "/api/v1/songs?enrichmentStatus=skipped", wantStatus: http.StatusOK, check: wantSongIDs(1, 2, 3, 4)},
+		{name: "combined filters", method: http.MethodGet, target: "/api/v1/songs?genre=rock&group=queen", wantStatus: http.StatusOK, check: wantSongIDs(3)},
+		{name: "nothing found", method: http.MethodGet, target: "/api/v1/songs?group=nirvana", wantStatus: http.StatusOK, check: checkAll(
+			wantSongIDs(),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+					t.Fatalf("body = %s, want []", body)
+				}
+			},
+		)},
+		{name: "limit", method: http.MethodGet, target: "/api/v1/songs?limit=2", wantStatus: http.StatusOK, check: wantSongIDs(1, 2)},
+		{name: "limit and offset", method: http.MethodGet, target: "/api/v1/songs?limit=2&offset=2", wantStatus: http.StatusOK, check: wantSongIDs(3, 4)},
+		{name: "offset past the end", method: http.MethodGet, target: "/api/v1/songs?limit=2&offset=10", wantStatus: http.StatusOK, check: wantSongIDs()},
+		{name: "sort by song", method: http.MethodGet, target: "/api/v1/songs?sort=song", wantStatus: http.StatusOK, check: wantSongIDs(3, 2, 5, 1, 4)},
+		{name: "sort descending with tie break", method: http.MethodGet, target: "/api/v1/songs?sort=-group", wantStatus: http.StatusOK, check: wantSongIDs(4, 3, 5, 1, 2)},
+		{name: "sort by release date", method: http.MethodGet, target: "/api/v1/songs?sort=-releaseDate&limit=2", wantStatus: http.StatusOK, check: wantSongIDs(1, 2)},
+		{name: "filters from deprecated headers", method: http.MethodGet, target: "/api/v1/songs", header: http.Header{"Genre": {"post-punk"}}, wantStatus: http.StatusOK, check: checkAll(
+			wantSongIDs(4),
+			wantHeader("Deprecation", "true"),
+		)},
+		{name: "query wins over header", method: http.MethodGet, target: "/api/v1/songs?genre=pop", header: http.Header{"Genre": {"post-punk"}}, wantStatus: http.StatusOK, check: checkAll(
+			wantSongIDs(5),
+			wantHeader("Deprecation", ""),
+		)},
+		{name: "unknown sort field", method: http.MethodGet, target: "/api/v1/songs?sort=rating", wantStatus: http.StatusBadRequest},
+		{name: "invalid release date", method: http.MethodGet, target: "/api/v1/songs?releaseDate=yesterday", wantStatus: http.StatusBadRequest},
+		{name: "invalid decade", method: http.MethodGet, target: "/api/v1/songs?decade=1985", wantStatus: http.StatusBadRequest},
+		{name: "zero decade", method: http.MethodGet, target: "/api/v1/songs?decade=0", wantStatus: http.StatusBadRequest},
+		{name: "signed decade", method: http.MethodGet, target: "/api/v1/songs?decade=%2B90s", wantStatus: http.StatusBadRequest},
+		{name: "invalid year", method: http.MethodGet, target: "/api/v1/songs?year=abc", wantStatus: http.StatusBadRequest},
+		{name: "negative limit", method: http.MethodGet, target: "/api/v1/songs?limit=-1", wantStatus: http.StatusBadRequest},
+		{name: "limit above maximum", method: http.MethodGet, target: "/api/v1/songs?limit=101", wantStatus: http.StatusBadRequest},
+		{name: "unknown enrichment status", method: http.MethodGet, target: "/api/v1/songs?enrichmentStatus=done", wantStatus: http.StatusBadRequest},
+		{name: "first cursor page", method: http.MethodGet, target: "/api/v1/songs?cursor=&limit=2", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var page dto.SongsPage
+			decode(t, rec, &page)
+
+			assertIDs(t, songIDs(page.Songs), []int{1, 2})
+			if page.NextCursor == "" {
+				t.Fatal("next_cursor is empty")
+			}
+		}},
+		{name: "invalid cursor", method: http.MethodGet, target: "/api/v1/songs?cursor=garbage", wantStatus: http.StatusBadRequest},
+		{name: "cursor with offset", method: http.MethodGet, target: "/api/v1/songs?cursor=&offset=1", wantStatus: http.StatusBadRequest},
+	})
+}
+
+func TestGetSongsCursorPagination(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, sort := range []string{"", "-group", "releaseDate", "genre,-song"} {
+		t.Run("sort="+sort, func(t *testing.T) {
+			rec := env.do(t, http.MethodGet, "/api/v1/songs?sort="+sort, "", nil)
+			var all []model.Song
+			decode(t, rec, &all)
+
+			var paged []int
+			cursor := ""
+			for pages := 0; ; pages++ {
+				if pages > len(all) {
+					t.Fatal("pagination does not terminate")
+				}
+
+				rec := env.do(t, http.MethodGet, "/api/v1/songs?limit=2&sort="+sort+"&cursor="+cursor, "", nil)
+				if rec.Code != http.StatusOK {
+					t.Fatalf("status %d: %s", rec.Code, rec.Body)
+				}
+
+				var page dto.SongsPage
+				decode(t, rec, &page)
+				paged = append(paged, songIDs(page.Songs)...)
+
+				if page.NextCursor == "" {
+					break
+				}
+				cursor = page.NextCursor
+			}
+
+			assertIDs(t, paged, songIDs(all))
+		})
+	}
+
+	t.Run("cursor from another sort", func(t *testing.T) {
+		rec := env.do(t, http.MethodGet, "/api/v1/songs?limit=1&cursor=", "", nil)
+		var page dto.SongsPage
+		decode(t, rec, &page)
+
+		rec = env.do(t, http.MethodGet, "/api/v1/songs?limit=1&sort=song&cursor="+page.NextCursor, "", nil)
+		if rec.Code != http.StatusBadRequest {
+			t.Fatalf("status %d, want 400", rec.Code)
+		}
+	})
+}
+
+func TestGetSongById(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "existing song", method: http.MethodGet, target: "/api/v1/song/3", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var song model.Song
+			decode(t, rec, &song)
+
+			if song.Song != "Bohemian Rhapsody" || song.Group != "Queen" || song.GroupID != 2 ||
+				song.ReleaseDate != "31.10.1975" || song.EnrichmentStatus != model.EnrichmentSkipped {
+				t.Fatalf("song = %+v", song)
+			}
+		}},
+		{name: "unknown song", method: http.MethodGet, target: "/api/v1/song/99", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, target: "/api/v1/song/abc", wantStatus: http.StatusBadRequest},
+	})
+}
+
+func TestGetSongLyrics(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "defaults to the first page", method: http.MethodGet, target: "/api/v1/song/2/lyrics", wantStatus: http.StatusOK, check: wantLyrics(
+			1, 3, false, "It's bugging me", "Grating me", "And twisting me around",
+		)},
+		{name: "first page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?page=1&limit=1", wantStatus: http.StatusOK, check: wantLyrics(
+			1, 3, true, "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?",
+		)},
+		{name: "second page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?page=2&limit=1", wantStatus: http.StatusOK, check: wantLyrics(
+			2, 3, true, "You caught me under false pretenses\nHow long before you let me go?",
+		)},
+		{name: "last full page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?page=3&limit=1", wantStatus: http.StatusOK, check: wantLyrics(
+			3, 3, false, "You set my soul alight",
+		)},
+		{name: "page of two verses", method: http.MethodGet, target: "/api/v1/song/2/lyrics?page=1&limit=2", wantStatus: http.StatusOK, check: wantLyrics(
+			1, 3, true, "It's bugging me", "Grating me",
+		)},
+		{name: "last partial page", method: http.MethodGet, target: "/api/v1/song/2/lyrics?page=2&limit=2", wantStatus: http.StatusOK, check: wantLyrics(
+			2, 3, false, "And twisting me around",
+		)},
+		{name: "page past the end is empty", method: http.MethodGet, target: "/api/v1/song/2/lyrics?page=3&limit=2", wantStatus: http.StatusOK, check: wantLyrics(
+			3, 3, false,
+		)},
+		{name: "huge page is empty", method: http.MethodGet, target: "/api/v1/song/2/lyrics?page=9223372036854775807&limit=100", wantStatus: http.StatusOK, check: wantLyrics(
+			9223372036854775807, 3, false,
+		)},
+		{name: "song without lyrics", method: http.MethodGet, target: "/api/v1/song/5/lyrics", wantStatus: http.StatusOK, check: wantLyrics(
+			1, 0, false,
+		)},
+		{name: "deprecated offset as page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?limit=1&offset=2", wantStatus: http.StatusOK, check: checkAll(
+			wantLyrics(2, 3, true, "You caught me under false pretenses\nHow long before you let me go?"),
+			wantHeader("Deprecation", "true"),
+		)},
+		{name: "deprecated zero offset is the first page", method: http.MethodGet, target: "/api/v1/song/3/lyrics?limit=1&offset=0", wantStatus: http.StatusOK, check: wantLyrics(
+			1, 2, true, "Is this the real life?",
+		)},
+		{name: "page wins over offset", method: http.MethodGet, target: "/api/v1/song/3/lyrics?page=1&limit=1&offset=2", wantStatus: http.StatusOK, check: checkAll(
+			wantLyrics(1, 2, true, "Is this the real life?"),
+			wantHeader("Deprecation", ""),
+		)},
+		{name: "pagination from deprecated headers", method: http.MethodGet, target: "/api/v1/song/3/lyrics", header: http.Header{"Limit": {"1"}, "Page": {"2"}}, wantStatus: http.StatusOK, check: checkAll(
+			wantLyrics(2, 2, false, "Is this just fantasy?"),
+			wantHeader("Deprecation", "true"),
+		)},
+		{name: "unknown song", method: http.MethodGet, target: "/api/v1/song/99/lyrics", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, target: "/api/v1/song/abc/lyrics", wantStatus: http.StatusBadRequest},
+		{name: "zero page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?page=0", wantStatus: http.StatusBadRequest},
+		{name: "negative page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?page=-1", wantStatus: http.StatusBadRequest},
+		{name: "zero limit", method: http.MethodGet, target: "/api/v1/song/1/lyrics?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "negative limit", method: http.MethodGet, target: "/api/v1/song/1/lyrics?limit=-1", wantStatus: http.StatusBadRequest},
+		{name: "limit too large", method: http.MethodGet, target: "/api/v1/song/1/lyrics?limit=101", wantStatus: http.StatusBadRequest},
+		{name: "non-numeric page", method: http.MethodGet, target: "/api/v1/song/1/lyrics?page=first", wantStatus: http.StatusBadRequest},
+		{name: "negative offset", method: http.MethodGet, target: "/api/v1/song/1/lyrics?offset=-1", wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("CRLF and extra blank lines", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		body := `{"id":3,"text":"\r\nIs this the real life?\r\nIs this just fantasy?\r\n\r\n  \r\n\r\nCaught in a landslide\r\n\r\n"}`
+		if rec := env.do(t, http.MethodPut, "/api/v1/song", body, nil); rec.Code != http.StatusOK {
+			t.Fatalf("update status %d: %s", rec.Code, rec.Body)
+		}
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/3/lyrics", "", nil)
+		wantLyrics(1, 2, false, "Is this the real life?\nIs this just fantasy?", "Caught in a landslide")(t, rec)
+	})
+}
+
+func TestSearchSongs(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "fulltext by group", method: http.MethodGet, target: "/api/v1/songs/search/muse", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var results []model.SongSearchResult
+			decode(t, rec, &results)
+
+			var songs []model.Song
+			for _, result := range results {
+				songs = append(songs, result.Song)
+			}
+			assertIDs(t, songIDs(songs), []int{1, 2})
+
+			if results[0].Highlights.Group == nil || *results[0].Highlights.Group != "<mark>Muse</mark>" {
+				t.Fatalf("group highlight = %v", results[0].Highlights.Group)
+			}
+		}},
+		{name: "fulltext by lyrics", method: http.MethodGet, target: "/api/v1/songs/search/fantasy", wantStatus: http.StatusOK, check: wantSongIDs(3)},
+		{name: "fulltext with exclusion", method: http.MethodGet, target: "/api/v1/songs/search/rock%20-queen", wantStatus: http.StatusOK, check: wantSongIDs(1, 2)},
+		{name: "fulltext sorted", method: http.MethodGet, target: "/api/v1/songs/search/rock?sort=-releaseDate", wantStatus: http.StatusOK, check: wantSongIDs(1, 2, 3)},
+		{name: "fulltext nothing found", method: http.MethodGet, target: "/api/v1/songs/search/nirvana", wantStatus: http.StatusOK, check: wantSongIDs()},
+		{name: "fuzzy with typo", method: http.MethodGet, target: "/api/v1/songs/search/hysterya?mode=fuzzy", wantStatus: http.StatusOK, check: wantSongIDs(2)},
+		{name: "fuzzy limit", method: http.MethodGet, target: "/api/v1/songs/search/queen?mode=fuzzy&limit=1", wantStatus: http.StatusOK, check: wantSongIDs(3)},
+		{name: "unknown mode", method: http.MethodGet, target: "/api/v1/songs/search/muse?mode=regex", wantStatus: http.StatusBadRequest},
+		{name: "blank query", method: http.MethodGet, target: "/api/v1/songs/search/%20", wantStatus: http.StatusBadRequest},
+		{name: "limit out of range", method: http.MethodGet, target: "/api/v1/songs/search/muse?mode=fuzzy&limit=1000", wantStatus: http.StatusBadRequest},
+	})
+}
+
+func TestSuggestSongs(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "songs and groups", method: http.MethodGet, target: "/api/v1/songs/suggest?prefix=h", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var suggestions []model.Suggestion
+			decode(t, rec, &suggestions)
+
+			if len(suggestions) != 1 || suggestions[0].Type != "song" || suggestions[0].Value != "Hysteria" {
+				t.Fatalf("suggestions = %+v", suggestions)
+			}
+		}},
+		{name: "group", method: http.MethodGet, target: "/api/v1/songs/suggest?prefix=qu", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var suggestions []model.Suggestion
+			decode(t, rec, &suggestions)
+
+			if len(suggestions) != 1 || suggestions[0].Type != "group" || suggestions[0].ID != 2 {
+				t.Fatalf("suggestions = %+v", suggestions)
+			}
+		}},
+		{name: "limit", method: http.MethodGet, target: "/api/v1/songs/suggest?prefix=r&limit=1", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var suggestions []model.Suggestion
+			decode(t, rec, &suggestions)
+
+			if len(suggestions) != 1 {
+				t.Fatalf("suggestions = %+v", suggestions)
+			}
+		}},
+		{name: "missing prefix", method: http.MethodGet, target: "/api/v1/songs/suggest", wantStatus: http.StatusBadRequest},
+		{name: "blank prefix", method: http.MethodGet, target: "/api/v1/songs/suggest?prefix=%20%20", wantStatus: http.StatusBadRequest},
+	})
+}
+
+func TestAddSong(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "complete song is not enriched", method: http.MethodPost, target: "/api/v1/song", body: `{"group":"Muse","song":"Uprising","genre":"rock","releaseDate":"2009-09-07","text":"Paranoia is in bloom","link":"https://example.com/uprising"}`, wantStatus: http.StatusAccepted, check: checkAll(
+			wantHeader("Location", "/api/v1/song/6"),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var result dto.AddSongResult
+				decode(t, rec, &result)
+
+				if result.Id != 6 || result.Enrich != model.EnrichMissing || result.EnrichmentStatus != model.EnrichmentSkipped {
+					t.Fatalf("result = %+v", result)
+				}
+			},
+		)},
+		{name: "missing fields are queued", method: http.MethodPost, target: "/api/v1/song", body: `{"group":"Muse","song":"Uprising"}`, wantStatus: http.StatusAccepted, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var result dto.AddSongResult
+			decode(t, rec, &result)
+
+			if result.EnrichmentStatus != model.EnrichmentPending {
+				t.Fatalf("result = %+v", result)
+			}
+		}},
+		{name: "enrich never", method: http.MethodPost, target: "/api/v1/song?enrich=never", body: `{"group":"Muse","song":"Uprising"}`, wantStatus: http.StatusAccepted, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var result dto.AddSongResult
+			decode(t, rec, &result)
+
+			if result.Enrich != model.EnrichNever || result.EnrichmentStatus != model.EnrichmentSkipped {
+				t.Fatalf("result = %+v", result)
+			}
+		}},
+		{name: "unknown enrich policy", method: http.MethodPost, target: "/api/v1/song?enrich=sometimes", body: `{"group":"Muse","song":"Uprising"}`, wantStatus: http.StatusBadRequest},
+		{name: "missing group", method: http.MethodPost, target: "/api/v1/song", body: `{"song":"Uprising"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid json", method: http.MethodPost, target: "/api/v1/song", body: `{"group":`, wantStatus: http.StatusBadRequest},
+		{name: "invalid release date", method: http.MethodPost, target: "/api/v1/song", body: `{"group":"Muse","song":"Uprising","releaseDate":"someday"}`, wantStatus: http.StatusBadRequest},
+	})
+}
+
+func TestUpdateSong(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "partial update", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"genre":"alternative rock"}`, wantStatus: http.StatusOK},
+		{name: "unknown song", method: http.MethodPut, target: "/api/v1/song", body: `{"id":99,"genre":"rock"}`, wantStatus: http.StatusNotFound},
+		{name: "missing id", method: http.MethodPut, target: "/api/v1/song", body: `{"genre":"rock"}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid json", method: http.MethodPut, target: "/api/v1/song", body: `{"id":`, wantStatus: http.StatusBadRequest},
+		{name: "invalid release date", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"releaseDate":"someday"}`, wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("changes are visible and other fields are kept", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		rec := env.do(t, http.MethodPut, "/api/v1/song", `{"id":1,"genre":"alternative rock","releaseDate":"2006-06-20","group":"Radiohead"}`, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/1", "", nil), &song)
+
+		if song.Genre != "alternative rock" || song.ReleaseDate != "20.06.2006" || song.Group != "Radiohead" || song.GroupID != 4 {
+			t.Fatalf("song = %+v", song)
+		}
+		if song.Song != "Supermassive Black Hole" || song.Link != "https://www.youtube.com/watch?v=Xsp3_a-PMTw" {
+			t.Fatalf("untouched fields changed: %+v", song)
+		}
+	})
+
+	t.Run("unchanged fields keep version and history", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		etag := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil).Header().Get("ETag")
+
+		// Дата в другом формате и текст с CRLF совпадают с сохраненными после нормализации
+		body := `{"id":1,"song":"Supermassive Black Hole","genre":"rock","releaseDate":"19.06.2006","group":"Muse",` +
+			`"text":"Ooh baby, don't you know I suffer?\r\nOoh baby, can you hear me moan?\r\n\r\n` +
+			`You caught me under false pretenses\r\nHow long before you let me go?\r\n\r\nYou set my soul alight",` +
+			`"link":"https://www.youtube.com/watch?v=Xsp3_a-PMTw"}`
+		rec := env.do(t, http.MethodPut, "/api/v1/song", body, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		wantHeader("ETag", etag)(t, rec)
+
+		if got := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil).Header().Get("ETag"); got != etag {
+			t.Fatalf("etag = %s, want %s", got, etag)
+		}
+		assertProblem(t, env.do(t, http.MethodGet, "/api/v1/song/1/revisions/2", "", nil), http.StatusNotFound)
+
+		// Совпадающие поля не мешают изменению остальных
+		env.do(t, http.MethodPut, "/api/v1/song", `{"id":1,"genre":"alternative rock","group":"Muse"}`, nil)
+
+		var revision model.SongRevision
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/1/revisions/2", "", nil), &revision)
+		if revision.Genre != "alternative rock" || revision.Group != "Muse" {
+			t.Fatalf("revision = %+v", revision)
+		}
+	})
+
+	t.Run("new group is created", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodPut, "/api/v1/song", `{"id":2,"group":"Nirvana"}`, nil)
+
+		rec := env.do(t, http.MethodGet, "/api/v1/songs?group=nirvana", "", nil)
+		wantSongIDs(2)(t, rec)
+	})
+}
+
+func TestDeleteSong(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "existing song", method: http.MethodDelete, target: "/api/v1/song/2", wantStatus: http.StatusOK},
+		{name: "unknown song", method: http.MethodDelete, target: "/api/v1/song/99", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodDelete, target: "/api/v1/song/abc", wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("deleted song is gone", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		if rec := env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("delete status %d", rec.Code)
+		}
+		if rec := env.do(t, http.MethodGet, "/api/v1/song/2", "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("get status %d, want 404", rec.Code)
+		}
+		if rec := env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("second delete status %d, want 404", rec.Code)
+		}
+
+		wantSongIDs(1, 3, 4, 5)(t, env.do(t, http.MethodGet, "/api/v1/songs", "", nil))
+	})
+}
+
+func TestConditionalRequests(t *testing.T) {
+	ifMatch := func(tag string) http.Header { return http.Header{"If-Match": {tag}} }
+	ifNoneMatch := func(tag string) http.Header { return http.Header{"If-None-Match": {tag}} }
+
+	runRouteTests(t, []routeTest{
+		{name: "get returns etag", method: http.MethodGet, target: "/api/v1/song/1", wantStatus: http.StatusOK, check: checkAll(
+			wantHeader("ETag", `"1"`),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var song model.Song
+				decode(t, rec, &song)
+				if song.Version != 1 {
+					t.Fatalf("version = %d, want 1", song.Version)
+				}
+			},
+		)},
+		{name: "synced lyrics import changes version", method: http.MethodGet, target: "/api/v1/song/2", wantStatus: http.StatusOK, check: wantHeader("ETag", `"2"`)},
+		{name: "not modified", method: http.MethodGet, target: "/api/v1/song/1", header: ifNoneMatch(`"1"`), wantStatus: http.StatusNotModified, check: checkAll(
+			wantHeader("ETag", `"1"`),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				if rec.Body.Len() != 0 {
+					t.Fatalf("body = %s, want empty", rec.Body)
+				}
+			},
+		)},
+		{name: "not modified with weak etag", method: http.MethodGet, target: "/api/v1/song/1", header: ifNoneMatch(`"7", W/"1"`), wantStatus: http.StatusNotModified},
+		{name: "not modified with wildcard", method: http.MethodGet, target: "/api/v1/song/1", header: ifNoneMatch("*"), wantStatus: http.StatusNotModified},
+		{name: "modified", method: http.MethodGet, target: "/api/v1/song/2", header: ifNoneMatch(`"1"`), wantStatus: http.StatusOK},
+		{name: "update with matching if-match", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"genre":"pop"}`, header: ifMatch(`"1"`), wantStatus: http.StatusOK, check: wantHeader("ETag", `"2"`)},
+		{name: "update with one of several etags", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"genre":"pop"}`, header: ifMatch(`"5", "1"`), wantStatus: http.StatusOK},
+		{name: "update with wildcard", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"genre":"pop"}`, header: ifMatch("*"), wantStatus: http.StatusOK},
+		{name: "update without if-match", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"genre":"pop"}`, wantStatus: http.StatusOK, check: wantHeader("ETag", `"2"`)},
+		{name: "update with stale etag", method: http.MethodPut, target: "/api/v1/song", body: `{"id":2,"genre":"pop"}`, header: ifMatch(`"1"`), wantStatus: http.StatusPreconditionFailed},
+		{name: "update with weak etag", method: http.MethodPut, target: "/api/v1/song", body: `{"id":1,"genre":"pop"}`, header: ifMatch(`W/"1"`), wantStatus: http.StatusPreconditionFailed},
+		{name: "update unknown song", method: http.MethodPut, target: "/api/v1/song", body: `{"id":99,"genre":"pop"}`, header: ifMatch(`"1"`), wantStatus: http.StatusNotFound},
+		{name: "delete with matching if-match", method: http.MethodDelete, target: "/api/v1/song/2", header: ifMatch(`"2"`), wantStatus: http.StatusOK},
+		{name: "delete with stale etag", method: http.MethodDelete, target: "/api/v1/song/2", header: ifMatch(`"1"`), wantStatus: http.StatusPreconditionFailed},
+	})
+
+	t.Run("concurrent editors", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		etag := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil).Header().Get("ETag")
+
+		rec := env.do(t, http.MethodPut, "/api/v1/song", `{"id":1,"genre":"alternative rock"}`, ifMatch(etag))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("first update: status %d: %s", rec.Code, rec.Body)
+		}
+		newETag := rec.Header().Get("ETag")
+		if newETag == etag {
+			t.Fatalf("etag did not change: %s", etag)
+		}
+
+		rec = env.do(t, http.MethodPut, "/api/v1/song", `{"id":1,"genre":"pop"}`, ifMatch(etag))
+		if rec.Code != http.StatusPreconditionFailed {
+			t.Fatalf("second update: status %d, want 412; body: %s", rec.Code, rec.Body)
+		}
+		assertProblem(t, rec, http.StatusPreconditionFailed)
+
+		rec = env.do(t, http.MethodGet, "/api/v1/song/1", "", ifNoneMatch(etag))
+		wantHeader("ETag", newETag)(t, rec)
+
+		var song model.Song
+		decode(t, rec, &song)
+		if song.Genre != "alternative rock" {
+			t.Fatalf("genre = %q, the stale update must not be applied", song.Genre)
+		}
+
+		if rec := env.do(t, http.MethodDelete, "/api/v1/song/1", "", ifMatch(etag)); rec.Code != http.StatusPreconditionFailed {
+			t.Fatalf("delete with stale etag: status %d, want 412", rec.Code)
+		}
+		if rec := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("song was deleted despite stale etag: status %d", rec.Code)
+		}
+	})
+
+	t.Run("related changes bump version", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		changes := []struct{ method, target, body string }{
+			{http.MethodPut, "/api/v1/song/1/verses/1", `{"text":"Ooh baby"}`},
+			{http.MethodPut, "/api/v1/song/1/lyrics/synced", "[00:01.00]Ooh baby"},
+			{http.MethodDelete, "/api/v1/song/1/lyrics/synced", ""},
+			{http.MethodPut, "/api/v1/groups/1", `{"name":"MUSE"}`},
+		}
+		for _, change := range changes {
+			etag := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil).Header().Get("ETag")
+
+			if rec := env.do(t, change.method, change.target, change.body, nil); rec.Code >= http.StatusBadRequest {
+				t.Fatalf("%s %s: status %d: %s", change.method, change.target, rec.Code, rec.Body)
+			}
+
+			if rec := env.do(t, http.MethodGet, "/api/v1/song/1", "", ifNoneMatch(etag)); rec.Code != http.StatusOK {
+				t.Fatalf("after %s %s: status %d, want 200", change.method, change.target, rec.Code)
+			}
+		}
+
+		etag := env.do(t, http.MethodGet, "/api/v1/song/3", "", nil).Header().Get("ETag")
+		env.do(t, http.MethodPut, "/api/v1/song/3/translations/en", `{"text":"Is this the real life?"}`, nil)
+		if rec := env.do(t, http.MethodGet, "/api/v1/song/3", "", ifNoneMatch(etag)); rec.Code != http.StatusNotModified {
+			t.Fatalf("translation changed song version: status %d, want 304", rec.Code)
+		}
+	})
+}
+
+func TestTrash(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "empty trash", method: http.MethodGet, target: "/api/v1/songs/trash", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+				t.Fatalf("body = %s, want []", body)
+			}
+		}},
+		{name: "restore song not in trash", method: http.MethodPost, target: "/api/v1/songs/trash/1/restore", wantStatus: http.StatusNotFound},
+		{name: "restore unknown song", method: http.MethodPost, target: "/api/v1/songs/trash/99/restore", wantStatus: http.StatusNotFound},
+		{name: "restore with invalid id", method: http.MethodPost, target: "/api/v1/songs/trash/abc/restore", wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("deleted song is hidden everywhere", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		if rec := env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("delete status %d", rec.Code)
+		}
+
+		hidden := []struct{ method, target, body string }{
+			{http.MethodGet, "/api/v1/song/2", ""},
+			{http.MethodGet, "/api/v1/song/2/lyrics", ""},
+			{http.MethodGet, "/api/v1/song/2/lyrics/synced", ""},
+			{http.MethodGet, "/api/v1/song/2/verses", ""},
+			{http.MethodGet, "/api/v1/song/2/verses/4", ""},
+			{http.MethodGet, "/api/v1/song/2/translations", ""},
+			{http.MethodGet, "/api/v1/song/2/revisions", ""},
+			{http.MethodPut, "/api/v1/song", `{"id":2,"genre":"pop"}`},
+			{http.MethodPost, "/api/v1/song/2/verses", `{"text":"Hysteria"}`},
+			{http.MethodPost, "/api/v1/song/2/enrich", ""},
+			{http.MethodPut, "/api/v1/song/2/translations/ru", `{"text":"Это гложет меня"}`},
+		}
+		for _, request := range hidden {
+			rec := env.do(t, request.method, request.target, request.body, nil)
+			if rec.Code != http.StatusNotFound {
+				t.Errorf("%s %s: status %d, want 404; body: %s", request.method, request.target, rec.Code, rec.Body)
+			}
+		}
+
+		wantSongIDs(1, 3, 4, 5)(t, env.do(t, http.MethodGet, "/api/v1/songs", "", nil))
+		wantSongIDs(1)(t, env.do(t, http.MethodGet, "/api/v1/songs?group=muse", "", nil))
+		wantSongIDs(1)(t, env.do(t, http.MethodGet, "/api/v1/groups/1/songs", "", nil))
+
+		rec := env.do(t, http.MethodGet, "/api/v1/songs/search/hysteria", "", nil)
+		if rec.Code != http.StatusOK || strings.TrimSpace(rec.Body.String()) != "[]" {
+			t.Fatalf("search: status %d, body %s", rec.Code, rec.Body)
+		}
+
+		rec = env.do(t, http.MethodGet, "/api/v1/songs/suggest?prefix=hyst", "", nil)
+		if strings.Contains(rec.Body.String(), "Hysteria") {
+			t.Fatalf("suggest: %s", rec.Body)
+		}
+	})
+
+	t.Run("trash lists deleted songs", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil)
+		env.do(t, http.MethodDelete, "/api/v1/song/4", "", nil)
+
+		var trash []dto.TrashedSong
+		decode(t, env.do(t, http.MethodGet, "/api/v1/songs/trash", "", nil), &trash)
+
+		if len(trash) != 2 || trash[0].ID != 4 || trash[1].ID != 2 {
+			t.Fatalf("trash = %+v", trash)
+		}
+		for _, song := range trash {
+			if song.DeletedAt == nil || !song.PurgeAt.After(*song.DeletedAt) {
+				t.Fatalf("song %d: deletedAt %v, purgeAt %v", song.ID, song.DeletedAt, song.PurgeAt)
+			}
+		}
+		// Удаление увеличивает версию песни: 2 после импорта синхронизированного текста
+		if trash[1].Song.Song != "Hysteria" || trash[1].Group != "Muse" || trash[1].Version != 3 {
+			t.Fatalf("trashed song = %+v", trash[1])
+		}
+	})
+
+	t.Run("restored song comes back with its data", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil)
+
+		rec := env.do(t, http.MethodPost, "/api/v1/songs/trash/2/restore", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("restore status %d: %s", rec.Code, rec.Body)
+		}
+
+		var song model.Song
+		decode(t, rec, &song)
+		if song.ID != 2 || song.DeletedAt != nil || !song.HasSyncedLyrics || song.Version != 4 || strings.Contains(rec.Body.String(), "deletedAt") {
+			t.Fatalf("restored song: %s", rec.Body)
+		}
+
+		wantSongIDs(1, 2, 3, 4, 5)(t, env.do(t, http.MethodGet, "/api/v1/songs", "", nil))
+		wantVerseOrder([]int{4, 5, 6}, "It's bugging me", "Grating me", "And twisting me around")(t, env.do(t, http.MethodGet, "/api/v1/song/2/verses", "", nil))
+
+		if rec := env.do(t, http.MethodGet, "/api/v1/songs/trash", "", nil); strings.TrimSpace(rec.Body.String()) != "[]" {
+			t.Fatalf("trash after restore: %s", rec.Body)
+		}
+		if rec := env.do(t, http.MethodPost, "/api/v1/songs/trash/2/restore", "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("second restore status %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("group with songs in trash is kept", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/4", "", nil)
+
+		rec := env.do(t, http.MethodDelete, "/api/v1/groups/3", "", nil)
+		assertProblem(t, rec, http.StatusConflict)
+	})
+
+	t.Run("purge removes songs after retention", func(t *testing.T) {
+		t.Setenv("TRASH_RETENTION", "1ms")
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil)
+		time.Sleep(5 * time.Millisecond)
+
+		purged, err := env.services.PurgeTrash(context.Background())
+		if err != nil || purged != 1 {
+			t.Fatalf("PurgeTrash = %d, %v; want 1", purged, err)
+		}
+		if rec := env.do(t, http.MethodPost, "/api/v1/songs/trash/2/restore", "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("restore after purge: status %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("purge keeps songs within retention", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/2", "", nil)
+
+		purged, err := env.services.PurgeTrash(context.Background())
+		if err != nil || purged != 0 {
+			t.Fatalf("PurgeTrash = %d, %v; want 0", purged, err)
+		}
+	})
+}
+
+func TestEnrichSong(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "existing song", method: http.MethodPost, target: "/api/v1/song/1/enrich", wantStatus: http.StatusAccepted},
+		{name: "with policy", method: http.MethodPost, target: "/api/v1/song/1/enrich?enrich=always", wantStatus: http.StatusAccepted},
+		{name: "unknown song", method: http.MethodPost, target: "/api/v1/song/99/enrich", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodPost, target: "/api/v1/song/abc/enrich", wantStatus: http.StatusBadRequest},
+		{name: "unknown policy", method: http.MethodPost, target: "/api/v1/song/1/enrich?enrich=sometimes", wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("queued song is enriched from the external API", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.api.SetSongDetails("Queen", "Radio Ga Ga", dto.SongDetails{
+			ReleaseDate: "23.01.1984",
+			Text:        "I'd sit alone and watch your light",
+			Link:        "https://www.youtube.com/watch?v=azdwsXLmrHE",
+		})
+
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+
+		if song.EnrichmentStatus != model.EnrichmentEnriched || song.Text != "I'd sit alone and watch your light" {
+			t.Fatalf("song = %+v", song)
+		}
+		if strings.Join(song.EnrichedFields, ",") != "link,text" || song.EnrichmentSources[model.FieldText] != fakeinfo.Provider {
+			t.Fatalf("enrichedFields = %v, sources = %v", song.EnrichedFields, song.EnrichmentSources)
+		}
+
+		// Поле, измененное клиентом, больше не считается взятым из внешнего API
+		env.do(t, http.MethodPut, "/api/v1/song", `{"id":5,"text":"All we hear is radio ga ga"}`, nil)
+
+		var updated model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &updated)
+
+		if strings.Join(updated.EnrichedFields, ",") != "link" {
+			t.Fatalf("enrichedFields = %v, want [link]", updated.EnrichedFields)
+		}
+		if _, ok := updated.EnrichmentSources[model.FieldText]; ok {
+			t.Fatalf("sources = %v still contain text", updated.EnrichmentSources)
+		}
+	})
+
+	t.Run("client edit during enrichment is kept", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.api.SetSongDetails("Queen", "Radio Ga Ga", dto.SongDetails{
+			Text: "I'd sit alone and watch your light",
+			Link: "https://www.youtube.com/watch?v=azdwsXLmrHE",
+		})
+		// Клиент меняет текст, пока обработчик ждет ответа внешнего API
+		env.api.SetOnCall(func(group, song string) {
+			env.api.SetOnCall(nil)
+			if rec := env.do(t, http.MethodPut, "/api/v1/song", `{"id":5,"text":"All we hear is radio ga ga"}`, nil); rec.Code != http.StatusOK {
+				t.Errorf("update status %d: %s", rec.Code, rec.Body)
+			}
+		})
+
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+		if song.Text != "All we hear is radio ga ga" || song.EnrichmentStatus != model.EnrichmentPending {
+			t.Fatalf("song = %+v, want client text and pending status", song)
+		}
+
+		// Песня сразу вернулась в очередь и дополняется по новому состоянию
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+		if song.EnrichmentStatus != model.EnrichmentEnriched || song.Text != "All we hear is radio ga ga" || song.Link != "https://www.youtube.com/watch?v=azdwsXLmrHE" {
+			t.Fatalf("song = %+v", song)
+		}
+		if strings.Join(song.EnrichedFields, ",") != "link" {
+			t.Fatalf("enrichedFields = %v, want [link]", song.EnrichedFields)
+		}
+		if calls := env.api.Calls(); len(calls) != 2 {
+			t.Fatalf("calls = %+v, want 2", calls)
+		}
+	})
+
+	t.Run("request during enrichment applies the new policy", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.api.SetSongDetails("Queen", "Radio Ga Ga", dto.SongDetails{
+			ReleaseDate: "01.01.1984",
+			Link:        "https://www.youtube.com/watch?v=azdwsXLmrHE",
+		})
+		// Песня уже ожидает дополнения, но клиент запрашивает его заново с другой политикой
+		env.api.SetOnCall(func(group, song string) {
+			env.api.SetOnCall(nil)
+			if rec := env.do(t, http.MethodPost, "/api/v1/song/5/enrich?enrich=always", "", nil); rec.Code != http.StatusAccepted {
+				t.Errorf("enrich status %d: %s", rec.Code, rec.Body)
+			}
+		})
+
+		etag := env.do(t, http.MethodGet, "/api/v1/song/5", "", nil).Header().Get("ETag")
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		var song model.Song
+		rec := env.do(t, http.MethodGet, "/api/v1/song/5", "", nil)
+		decode(t, rec, &song)
+		if song.EnrichmentStatus != model.EnrichmentPending || song.Link != "" || rec.Header().Get("ETag") == etag {
+			t.Fatalf("song = %+v, etag %s: enrichment with the old policy must be dropped", song, rec.Header().Get("ETag"))
+		}
+
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		// По политике always заменяется и заданная при добавлении дата
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+		if song.EnrichmentStatus != model.EnrichmentEnriched || song.ReleaseDate != "01.01.1984" {
+			t.Fatalf("song = %+v", song)
+		}
+	})
+
+	t.Run("partial details are retried", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.api.SetSongDetails("Queen", "Radio Ga Ga", dto.SongDetails{
+			Link: "https://www.youtube.com/results?search_query=Queen+Radio+Ga+Ga", Partial: true,
+		})
+
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+		if song.EnrichmentStatus != model.EnrichmentPending || song.Link != "" {
+			t.Fatalf("song = %+v, want pending without fallback link", song)
+		}
+	})
+
+	t.Run("partial details are kept on the last attempt", func(t *testing.T) {
+		t.Setenv("ENRICHMENT_MAX_ATTEMPTS", "1")
+		env := newTestEnv(t)
+		link := "https://www.youtube.com/results?search_query=Queen+Radio+Ga+Ga"
+		env.api.SetSongDetails("Queen", "Radio Ga Ga", dto.SongDetails{Link: link, Partial: true})
+
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+		if song.EnrichmentStatus != model.EnrichmentEnriched || song.Link != link {
+			t.Fatalf("song = %+v, want fallback link", song)
+		}
+	})
+
+	t.Run("unknown song fails without retries", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		wantSongIDs(5)(t, env.do(t, http.MethodGet, "/api/v1/songs?enrichmentStatus=failed", "", nil))
+		if calls := env.api.Calls(); len(calls) != 1 || calls[0].Song != "Radio Ga Ga" {
+			t.Fatalf("calls = %+v", calls)
+		}
+	})
+}
+
+// wantVerseOrder проверяет идентификаторы, позиции и тексты куплетов в ответе-массиве
+func wantVerseOrder(ids []int, texts ...string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var verses []model.Verse
+		decode(t, rec, &verses)
+
+		var gotIds []int
+		var gotTexts []string
+		for i, verse := range verses {
+			if verse.Position != i+1 {
+				t.Fatalf("verse %d has position %d, want %d", verse.ID, verse.Position, i+1)
+			}
+			gotIds = append(gotIds, verse.ID)
+			gotTexts = append(gotTexts, verse.Text)
+		}
+
+		assertIDs(t, gotIds, ids)
+		if strings.Join(gotTexts, "|") != strings.Join(texts, "|") {
+			t.Fatalf("texts = %q, want %q", gotTexts, texts)
+		}
+	}
+}
+
+// Куплеты тестовых песен получают id по порядку: у песни 1 — 1..3, у песни 2 — 4..6,
+// у песни 3 — 7..8, у песни 4 — 9..10, у песни 5 куплетов нет
+func TestVerses(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "list", method: http.MethodGet, target: "/api/v1/song/2/verses", wantStatus: http.StatusOK, check: wantVerseOrder(
+			[]int{4, 5, 6}, "It's bugging me", "Grating me", "And twisting me around",
+		)},
+		{name: "list of song without lyrics", method: http.MethodGet, target: "/api/v1/song/5/verses", wantStatus: http.StatusOK, check: wantVerseOrder(nil)},
+		{name: "list of unknown song", method: http.MethodGet, target: "/api/v1/song/99/verses", wantStatus: http.StatusNotFound},
+		{name: "list with invalid song id", method: http.MethodGet, target: "/api/v1/song/abc/verses", wantStatus: http.StatusBadRequest},
+		{name: "get", method: http.MethodGet, target: "/api/v1/song/2/verses/5", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var verse model.Verse
+			decode(t, rec, &verse)
+
+			if verse.SongID != 2 || verse.Position != 2 || verse.Type != model.VerseTypeVerse || verse.Text != "Grating me" {
+				t.Fatalf("verse = %+v", verse)
+			}
+		}},
+		{name: "get verse of another song", method: http.MethodGet, target: "/api/v1/song/1/verses/5", wantStatus: http.StatusNotFound},
+		{name: "get with invalid verse id", method: http.MethodGet, target: "/api/v1/song/2/verses/abc", wantStatus: http.StatusBadRequest},
+		{name: "insert", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"position":2,"type":"chorus","text":"Cause I want it now\r\nI want it now  "}`, wantStatus: http.StatusCreated, check: checkAll(
+			wantHeader("Location", "/api/v1/song/2/verses/11"),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var verse model.Verse
+				decode(t, rec, &verse)
+
+				if verse.ID != 11 || verse.Position != 2 || verse.Type != model.VerseTypeChorus || verse.Text != "Cause I want it now\nI want it now" {
+					t.Fatalf("verse = %+v", verse)
+				}
+			},
+		)},
+		{name: "append", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"text":"Outro"}`, wantStatus: http.StatusCreated, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var verse model.Verse
+			decode(t, rec, &verse)
+
+			if verse.Position != 4 || verse.Type != model.VerseTypeVerse {
+				t.Fatalf("verse = %+v", verse)
+			}
+		}},
+		{name: "insert past the end", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"position":5,"text":"Too far"}`, wantStatus: http.StatusBadRequest},
+		{name: "insert with unknown type", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"type":"solo","text":"Guitar"}`, wantStatus: http.StatusBadRequest},
+		{name: "insert without text", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"type":"chorus"}`, wantStatus: http.StatusBadRequest},
+		{name: "insert blank text", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"text":" \n "}`, wantStatus: http.StatusBadRequest},
+		{name: "insert text with blank line", method: http.MethodPost, target: "/api/v1/song/2/verses", body: `{"text":"first\n\nsecond"}`, wantStatus: http.StatusBadRequest},
+		{name: "insert into unknown song", method: http.MethodPost, target: "/api/v1/song/99/verses", body: `{"text":"Hello"}`, wantStatus: http.StatusNotFound},
+		{name: "edit text", method: http.MethodPut, target: "/api/v1/song/2/verses/5", body: `{"text":"Grating me hard"}`, wantStatus: http.StatusOK},
+		{name: "edit type", method: http.MethodPut, target: "/api/v1/song/2/verses/5", body: `{"type":"bridge"}`, wantStatus: http.StatusOK},
+		{name: "edit nothing", method: http.MethodPut, target: "/api/v1/song/2/verses/5", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "edit with unknown type", method: http.MethodPut, target: "/api/v1/song/2/verses/5", body: `{"type":"solo"}`, wantStatus: http.StatusBadRequest},
+		{name: "edit verse of another song", method: http.MethodPut, target: "/api/v1/song/2/verses/1", body: `{"text":"Hello"}`, wantStatus: http.StatusNotFound},
+		{name: "reorder", method: http.MethodPut, target: "/api/v1/song/2/verses/order", body: `{"verseIds":[6,4,5]}`, wantStatus: http.StatusOK, check: wantVerseOrder(
+			[]int{6, 4, 5}, "And twisting me around", "It's bugging me", "Grating me",
+		)},
+		{name: "reorder missing verse", method: http.MethodPut, target: "/api/v1/song/2/verses/order", body: `{"verseIds":[4,5]}`, wantStatus: http.StatusBadRequest},
+		{name: "reorder duplicate verse", method: http.MethodPut, target: "/api/v1/song/2/verses/order", body: `{"verseIds":[4,4,5]}`, wantStatus: http.StatusBadRequest},
+		{name: "reorder verse of another song", method: http.MethodPut, target: "/api/v1/song/2/verses/order", body: `{"verseIds":[4,5,1]}`, wantStatus: http.StatusBadRequest},
+		{name: "reorder without verses", method: http.MethodPut, target: "/api/v1/song/2/verses/order", body: `{"verseIds":[]}`, wantStatus: http.StatusBadRequest},
+		{name: "reorder unknown song", method: http.MethodPut, target: "/api/v1/song/99/verses/order", body: `{"verseIds":[1]}`, wantStatus: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, target: "/api/v1/song/2/verses/5", wantStatus: http.StatusOK},
+		{name: "delete unknown verse", method: http.MethodDelete, target: "/api/v1/song/2/verses/99", wantStatus: http.StatusNotFound},
+		{name: "delete with invalid id", method: http.MethodDelete, target: "/api/v1/song/2/verses/abc", wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("song text follows verse edits", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		steps := []struct {
+			method, target, body string
+		}{
+			{http.MethodPost, "/api/v1/song/2/verses", `{"position":1,"type":"intro","text":"Intro"}`},
+			{http.MethodPut, "/api/v1/song/2/verses/5", `{"text":"Grating me hard"}`},
+			{http.MethodDelete, "/api/v1/song/2/verses/6", ""},
+			{http.MethodPut, "/api/v1/song/2/verses/order", `{"verseIds":[4,5,11]}`},
+		}
+		for _, step := range steps {
+			if rec := env.do(t, step.method, step.target, step.body, nil); rec.Code >= http.StatusBadRequest {
+				t.Fatalf("%s %s: status %d: %s", step.method, step.target, rec.Code, rec.Body)
+			}
+		}
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/2", "", nil), &song)
+		if song.Text != "It's bugging me\n\nGrating me hard\n\nIntro" {
+			t.Fatalf("text = %q", song.Text)
+		}
+
+		wantLyrics(1, 3, false, "It's bugging me", "Grating me hard", "Intro")(t, env.do(t, http.MethodGet, "/api/v1/song/2/lyrics", "", nil))
+		wantSongIDs(2)(t, env.do(t, http.MethodGet, "/api/v1/songs?text=grating%20me%20hard", "", nil))
+	})
+
+	t.Run("whole text replaces verses", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodPut, "/api/v1/song/2/verses/4", `{"type":"chorus"}`, nil)
+		env.do(t, http.MethodPut, "/api/v1/song", `{"id":2,"text":"One\r\n\r\n\r\nTwo  "}`, nil)
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/2/verses", "", nil)
+		wantVerseOrder([]int{11, 12}, "One", "Two")(t, rec)
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/2", "", nil), &song)
+		if song.Text != "One\n\nTwo" {
+			t.Fatalf("text = %q", song.Text)
+		}
+	})
+
+	t.Run("editing enriched lyrics makes them client data", func(t *testing.T) {
+		env := newTestEnv(t)
+		env.api.SetSongDetails("Queen", "Radio Ga Ga", dto.SongDetails{
+			Text: "I'd sit alone\n\nAnd watch your light",
+			Link: "https://www.youtube.com/watch?v=azdwsXLmrHE",
+		})
+		if _, err := env.services.ProcessPendingEnrichments(context.Background()); err != nil {
+			t.Fatalf("ProcessPendingEnrichments: %v", err)
+		}
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/5/verses", "", nil)
+		wantVerseOrder([]int{11, 12}, "I'd sit alone", "And watch your light")(t, rec)
+
+		env.do(t, http.MethodPut, "/api/v1/song/5/verses/12", `{"text":"And watch your light, my only friend"}`, nil)
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/5", "", nil), &song)
+		if strings.Join(song.EnrichedFields, ",") != "link" {
+			t.Fatalf("enrichedFields = %v, want [link]", song.EnrichedFields)
+		}
+	})
+
+	t.Run("type change bumps version without revision", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		etag := env.do(t, http.MethodGet, "/api/v1/song/2", "", nil).Header().Get("ETag")
+		if rec := env.do(t, http.MethodPut, "/api/v1/song/2/verses/5", `{"type":"bridge"}`, nil); rec.Code != http.StatusOK {
+			t.Fatalf("edit type: status %d: %s", rec.Code, rec.Body)
+		}
+
+		if got := env.do(t, http.MethodGet, "/api/v1/song/2", "", nil).Header().Get("ETag"); got == etag {
+			t.Fatalf("ETag = %s after type change, want new version", got)
+		}
+		assertProblem(t, env.do(t, http.MethodGet, "/api/v1/song/2/revisions/2", "", nil), http.StatusNotFound)
+
+		env.do(t, http.MethodPut, "/api/v1/song/2/verses/5", `{"type":"chorus","text":"Hysteria"}`, nil)
+		wantRevision(2, handler.ActorAnonymous, "")(t, env.do(t, http.MethodGet, "/api/v1/song/2/revisions/2", "", nil))
+	})
+}
+
+// wantSyncedLines проверяет время и текст строк синхронизированного текста
+func wantSyncedLines(lines ...string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var lyrics dto.SyncedLyrics
+		decode(t, rec, &lyrics)
+
+		got := []string{}
+		for _, line := range lyrics.Lines {
+			got = append(got, line.Time+" "+line.Text)
+		}
+		if strings.Join(got, "|") != strings.Join(lines, "|") {
+			t.Fatalf("lines = %q, want %q", got, lines)
+		}
+	}
+}
+
+// wantSyncedLineAt проверяет звучащую и следующую строки; пустая строка означает null
+func wantSyncedLineAt(index int, line, next string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var at dto.SyncedLineAt
+		decode(t, rec, &at)
+
+		text := func(line *dto.SyncedLine) string {
+			if line == nil {
+				return ""
+			}
+			return line.Time + " " + line.Text
+		}
+
+		if at.Index != index || text(at.Line) != line || text(at.Next) != next {
+			t.Fatalf("at = %+v, line = %q, next = %q; want %d, %q, %q", at, text(at.Line), text(at.Next), index, line, next)
+		}
+	}
+}
+
+func TestSyncedLyrics(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "get", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced", wantStatus: http.StatusOK, check: checkAll(
+			wantSyncedLines("00:10.50 It's bugging me", "00:14.00 Grating me", "00:17.25 And twisting me around", "00:21.00 "),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				var lyrics dto.SyncedLyrics
+				decode(t, rec, &lyrics)
+
+				if lyrics.SongId != 2 || lyrics.Lines[0].StartMs != 10500 {
+					t.Fatalf("lyrics = %+v", lyrics)
+				}
+			},
+		)},
+		{name: "song without synced lyrics", method: http.MethodGet, target: "/api/v1/song/1/lyrics/synced", wantStatus: http.StatusNotFound},
+		{name: "unknown song", method: http.MethodGet, target: "/api/v1/song/99/lyrics/synced", wantStatus: http.StatusNotFound},
+		{name: "invalid id", method: http.MethodGet, target: "/api/v1/song/abc/lyrics/synced", wantStatus: http.StatusBadRequest},
+		{name: "line at timestamp", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=00:15.00", wantStatus: http.StatusOK, check: wantSyncedLineAt(
+			1, "00:14.00 Grating me", "00:17.25 And twisting me around",
+		)},
+		{name: "line at line start", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=17.25", wantStatus: http.StatusOK, check: wantSyncedLineAt(
+			2, "00:17.25 And twisting me around", "00:21.00 ",
+		)},
+		{name: "before first line", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=5", wantStatus: http.StatusOK, check: wantSyncedLineAt(
+			-1, "", "00:10.50 It's bugging me",
+		)},
+		{name: "after last line", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=01:30", wantStatus: http.StatusOK, check: wantSyncedLineAt(
+			3, "00:21.00 ", "",
+		)},
+		{name: "invalid position", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=soon", wantStatus: http.StatusBadRequest},
+		{name: "negative position", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=-1", wantStatus: http.StatusBadRequest},
+		{name: "position with invalid seconds", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?at=00:75", wantStatus: http.StatusBadRequest},
+		{name: "position of song without synced lyrics", method: http.MethodGet, target: "/api/v1/song/1/lyrics/synced?at=5", wantStatus: http.StatusNotFound},
+		{name: "export", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?format=lrc", wantStatus: http.StatusOK, check: checkAll(
+			wantHeader("Content-Type", "text/plain; charset=utf-8"),
+			wantHeader("Content-Disposition", `attachment; filename="song-2.lrc"`),
+			func(t *testing.T, rec *httptest.ResponseRecorder) {
+				if rec.Body.String() != hysteriaLRC {
+					t.Fatalf("body = %q, want %q", rec.Body, hysteriaLRC)
+				}
+			},
+		)},
+		{name: "export song without synced lyrics", method: http.MethodGet, target: "/api/v1/song/1/lyrics/synced?format=lrc", wantStatus: http.StatusNotFound},
+		{name: "unknown format", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?format=srt", wantStatus: http.StatusBadRequest},
+		{name: "export with position", method: http.MethodGet, target: "/api/v1/song/2/lyrics/synced?format=lrc&at=5", wantStatus: http.StatusBadRequest},
+		{name: "import", method: http.MethodPut, target: "/api/v1/song/1/lyrics/synced",
+			body:       "[ar:Muse]\r\n[offset:+500]\r\n[00:31.00][00:01.00]Ooh baby\r\n[00:16.00]You caught me\r\n",
+			wantStatus: http.StatusOK, check: wantSyncedLines("00:00.50 Ooh baby", "00:15.50 You caught me", "00:30.50 Ooh baby"),
+		},
+		{name: "import replaces lyrics", method: http.MethodPut, target: "/api/v1/song/2/lyrics/synced", body: "[00:01.00]Only line",
+			wantStatus: http.StatusOK, check: wantSyncedLines("00:01.00 Only line"),
+		},
+		{name: "import line without time", method: http.MethodPut, target: "/api/v1/song/1/lyrics/synced", body: "[00:01.00]Ooh baby\nYou caught me", wantStatus: http.StatusBadRequest},
+		{name: "import tags only", method: http.MethodPut, target: "/api/v1/song/1/lyrics/synced", body: "[ar:Muse]\n[ti:Hysteria]", wantStatus: http.StatusBadRequest},
+		{name: "import empty", method: http.MethodPut, target: "/api/v1/song/1/lyrics/synced", wantStatus: http.StatusBadRequest},
+		{name: "import too large", method: http.MethodPut, target: "/api/v1/song/1/lyrics/synced",
+			body:       strings.Repeat("[00:01.00]la la la la la la la\n", 40000),
+			wantStatus: http.StatusRequestEntityTooLarge,
+		},
+		{name: "import into unknown song", method: http.MethodPut, target: "/api/v1/song/99/lyrics/synced", body: "[00:01.00]line", wantStatus: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, target: "/api/v1/song/2/lyrics/synced", wantStatus: http.StatusOK},
+		{name: "delete missing", method: http.MethodDelete, target: "/api/v1/song/1/lyrics/synced", wantStatus: http.StatusNotFound},
+		{name: "delete with invalid id", method: http.MethodDelete, target: "/api/v1/song/abc/lyrics/synced", wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("song reports synced lyrics", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		hasSynced := func(id string) bool {
+			var song model.Song
+			decode(t, env.do(t, http.MethodGet, "/api/v1/song/"+id, "", nil), &song)
+			return song.HasSyncedLyrics
+		}
+
+		if !hasSynced("2") || hasSynced("1") {
+			t.Fatalf("hasSyncedLyrics: song 2 = %v, song 1 = %v", hasSynced("2"), hasSynced("1"))
+		}
+
+		env.do(t, http.MethodPut, "/api/v1/song/1/lyrics/synced", "[00:01.00]Ooh baby", nil)
+		env.do(t, http.MethodDelete, "/api/v1/song/2/lyrics/synced", "", nil)
+
+		if hasSynced("2") || !hasSynced("1") {
+			t.Fatalf("hasSyncedLyrics after changes: song 2 = %v, song 1 = %v", hasSynced("2"), hasSynced("1"))
+		}
+
+		// Синхронизированный текст не меняет обычный
+		wantLyrics(1, 3, false, "Ooh baby, don't you know I suffer?\nOoh baby, can you hear me moan?",
+			"You caught me under false pretenses\nHow long before you let me go?", "You set my soul alight",
+		)(t, env.do(t, http.MethodGet, "/api/v1/song/1/lyrics", "", nil))
+	})
+}
+
+// wantTranslation проверяет язык и текст перевода в ответе
+func wantTranslation(songId int, lang, text string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var translation model.Translation
+		decode(t, rec, &translation)
+
+		if translation.SongID != songId || translation.Lang != lang || translation.Text != text {
+			t.Fatalf("translation = %+v, want %d %s %q", translation, songId, lang, text)
+		}
+	}
+}
+
+// wantTranslatedLyrics проверяет язык, куплеты перевода и сопоставленные им куплеты оригинала
+func wantTranslatedLyrics(lang string, verses, original []string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var lyrics dto.SongLyrics
+		decode(t, rec, &lyrics)
+
+		if lyrics.Lang != lang || strings.Join(lyrics.Verses, "|") != strings.Join(verses, "|") ||
+			strings.Join(lyrics.Original, "|") != strings.Join(original, "|") || (original == nil) != (lyrics.Original == nil) {
+			t.Fatalf("lyrics = %+v, want %s %q alongside %q", lyrics, lang, verses, original)
+		}
+		if got := rec.Header().Get("Content-Language"); got != lang {
+			t.Fatalf("Content-Language = %q, want %q", got, lang)
+		}
+	}
+}
+
+func TestTranslations(t *testing.T) {
+	kinoEn := []string{"A warm place, but the streets are waiting", "For the prints of our feet"}
+	kino := []string{"Теплое место, но улицы ждут", "Отпечатков наших ног"}
+
+	runRouteTests(t, []routeTest{
+		{name: "list", method: http.MethodGet, target: "/api/v1/song/4/translations", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var translations []model.Translation
+			decode(t, rec, &translations)
+
+			if len(translations) != 1 || translations[0].Lang != "en" || translations[0].UpdatedAt.IsZero() {
+				t.Fatalf("translations = %+v", translations)
+			}
+		}},
+		{name: "list of song without translations", method: http.MethodGet, target: "/api/v1/song/1/translations", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+				t.Fatalf("body = %s, want []", body)
+			}
+		}},
+		{name: "list of unknown song", method: http.MethodGet, target: "/api/v1/song/99/translations", wantStatus: http.StatusNotFound},
+		{name: "list with invalid id", method: http.MethodGet, target: "/api/v1/song/abc/translations", wantStatus: http.StatusBadRequest},
+		{name: "get", method: http.MethodGet, target: "/api/v1/song/4/translations/en", wantStatus: http.StatusOK, check: wantTranslation(
+			4, "en", "A warm place, but the streets are waiting\n\nFor the prints of our feet",
+		)},
+		{name: "get is exact", method: http.MethodGet, target: "/api/v1/song/4/translations/en-GB", wantStatus: http.StatusNotFound},
+		{name: "get missing", method: http.MethodGet, target: "/api/v1/song/4/translations/de", wantStatus: http.StatusNotFound},
+		{name: "get with invalid language", method: http.MethodGet, target: "/api/v1/song/4/translations/english!", wantStatus: http.StatusBadRequest},
+		{name: "create", method: http.MethodPut, target: "/api/v1/song/2/translations/ru", body: `{"text":"Это гложет меня\r\n\r\n\r\nРаздражает меня  "}`,
+			wantStatus: http.StatusCreated, check: checkAll(
+				wantHeader("Location", "/api/v1/song/2/translations/ru"),
+				wantTranslation(2, "ru", "Это гложет меня\n\nРаздражает меня"),
+			),
+		},
+		{name: "create canonicalizes language", method: http.MethodPut, target: "/api/v1/song/2/translations/pt-br", body: `{"text":"Está me incomodando"}`,
+			wantStatus: http.StatusCreated, check: checkAll(
+				wantHeader("Location", "/api/v1/song/2/translations/pt-BR"),
+				wantTranslation(2, "pt-BR", "Está me incomodando"),
+			),
+		},
+		{name: "replace", method: http.MethodPut, target: "/api/v1/song/4/translations/en", body: `{"text":"Blood type"}`,
+			wantStatus: http.StatusOK, check: wantTranslation(4, "en", "Blood type"),
+		},
+		{name: "save blank text", method: http.MethodPut, target: "/api/v1/song/2/translations/ru", body: `{"text":" \n\n "}`, wantStatus: http.StatusBadRequest},
+		{name: "save without text", method: http.MethodPut, target: "/api/v1/song/2/translations/ru", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "save with invalid language", method: http.MethodPut, target: "/api/v1/song/2/translations/und", body: `{"text":"?"}`, wantStatus: http.StatusBadRequest},
+		{name: "save for unknown song", method: http.MethodPut, target: "/api/v1/song/99/translations/ru", body: `{"text":"Привет"}`, wantStatus: http.StatusNotFound},
+		{name: "delete", method: http.MethodDelete, target: "/api/v1/song/4/translations/EN", wantStatus: http.StatusOK},
+		{name: "delete missing", method: http.MethodDelete, target: "/api/v1/song/4/translations/de", wantStatus: http.StatusNotFound},
+		{name: "delete from unknown song", method: http.MethodDelete, target: "/api/v1/song/99/translations/en", wantStatus: http.StatusNotFound},
+
+		{name: "aligned lyrics", method: http.MethodGet, target: "/api/v1/song/4/lyrics?lang=en", wantStatus: http.StatusOK, check: wantTranslatedLyrics(
+			"en", kinoEn, kino,
+		)},
+		{name: "aligned lyrics page", method: http.MethodGet, target: "/api/v1/song/4/lyrics?lang=en&page=2&limit=1", wantStatus: http.StatusOK, check: checkAll(
+			wantLyrics(2, 2, false, kinoEn[1]),
+			wantTranslatedLyrics("en", kinoEn[1:], kino[1:]),
+		)},
+		{name: "regional variant matches", method: http.MethodGet, target: "/api/v1/song/4/lyrics?lang=en-GB", wantStatus: http.StatusOK, check: wantTranslatedLyrics(
+			"en", kinoEn, kino,
+		)},
+		{name: "unaligned lyrics", method: http.MethodGet, target: "/api/v1/song/3/lyrics?lang=de", wantStatus: http.StatusOK, check: checkAll(
+			wantLyrics(1, 1, false, "Ist das das wahre Leben? Ist das nur Fantasie?"),
+			wantTranslatedLyrics("de", []string{"Ist das das wahre Leben? Ist das nur Fantasie?"}, nil),
+		)},
+		{name: "page past the end", method: http.MethodGet, target: "/api/v1/song/4/lyrics?lang=en&page=3&limit=1", wantStatus: http.StatusOK, check: wantLyrics(3, 2, false)},
+		{name: "missing translation", method: http.MethodGet, target: "/api/v1/song/4/lyrics?lang=ru", wantStatus: http.StatusNotFound},
+		{name: "other language is not a match", method: http.MethodGet, target: "/api/v1/song/3/lyrics?lang=en", wantStatus: http.StatusNotFound},
+		{name: "invalid language", method: http.MethodGet, target: "/api/v1/song/4/lyrics?lang=e_n!", wantStatus: http.StatusBadRequest},
+		{name: "translated lyrics of unknown song", method: http.MethodGet, target: "/api/v1/song/99/lyrics?lang=en", wantStatus: http.StatusNotFound},
+	})
+
+	t.Run("original lyrics have no language", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/4/lyrics", "", nil)
+		if strings.Contains(rec.Body.String(), `"lang"`) || strings.Contains(rec.Body.String(), `"original"`) || rec.Header().Get("Content-Language") != "" {
+			t.Fatalf("original lyrics: %s", rec.Body)
+		}
+	})
+
+	t.Run("translation goes away with the song", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/4", "", nil)
+		if rec := env.do(t, http.MethodGet, "/api/v1/song/4/translations/en", "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("status %d, want 404", rec.Code)
+		}
+	})
+}
+
+func wantRevision(revision int, actor, reason string) func(t *testing.T, rec *httptest.ResponseRecorder) {
+	return func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+
+		var songRevision model.SongRevision
+		decode(t, rec, &songRevision)
+
+		if songRevision.Revision != revision || songRevision.Actor != actor || songRevision.Reason != reason || songRevision.CreatedAt.IsZero() {
+			t.Fatalf("revision = %+v, want %d by %q (%q)", songRevision, revision, actor, reason)
+		}
+	}
+}
+
+func TestRevisions(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "list", method: http.MethodGet, target: "/api/v1/song/1/revisions", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var revisions []model.SongRevision
+			decode(t, rec, &revisions)
+
+			if len(revisions) != 1 || revisions[0].Revision != 1 || revisions[0].Actor != dto.ActorSystem {
+				t.Fatalf("revisions = %+v", revisions)
+			}
+			if revisions[0].Song != "Supermassive Black Hole" || revisions[0].Group != "Muse" || revisions[0].ReleaseDate != "19.06.2006" {
+				t.Fatalf("snapshot = %+v", revisions[0])
+			}
+		}},
+		{name: "list of unknown song", method: http.MethodGet, target: "/api/v1/song/99/revisions", wantStatus: http.StatusNotFound},
+		{name: "list with invalid id", method: http.MethodGet, target: "/api/v1/song/abc/revisions", wantStatus: http.StatusBadRequest},
+		{name: "get", method: http.MethodGet, target: "/api/v1/song/2/revisions/1", wantStatus: http.StatusOK, check: wantRevision(1, dto.ActorSystem, "")},
+		{name: "get missing", method: http.MethodGet, target: "/api/v1/song/2/revisions/2", wantStatus: http.StatusNotFound},
+		{name: "get with invalid revision", method: http.MethodGet, target: "/api/v1/song/2/revisions/last", wantStatus: http.StatusBadRequest},
+		{name: "diff of the same revision", method: http.MethodGet, target: "/api/v1/song/2/revisions/diff?from=1&to=1", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var diff dto.RevisionDiff
+			decode(t, rec, &diff)
+
+			if len(diff.Changes) != 0 {
+				t.Fatalf("changes = %+v", diff.Changes)
+			}
+		}},
+		{name: "diff without revisions", method: http.MethodGet, target: "/api/v1/song/2/revisions/diff?from=1", wantStatus: http.StatusBadRequest},
+		{name: "diff with missing revision", method: http.MethodGet, target: "/api/v1/song/2/revisions/diff?from=1&to=5", wantStatus: http.StatusNotFound},
+		{name: "restore missing revision", method: http.MethodPost, target: "/api/v1/song/2/revisions/7/restore", wantStatus: http.StatusNotFound},
+		{name: "restore with invalid json", method: http.MethodPost, target: "/api/v1/song/2/revisions/1/restore", body: `{"reason":`, wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("changes are recorded with actor and reason", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		header := http.Header{"X-Actor": {"editor@example.com"}, "X-Change-Reason": {"опечатка в жанре"}}
+		if rec := env.do(t, http.MethodPut, "/api/v1/song", `{"id":2,"genre":"alternative rock"}`, header); rec.Code != http.StatusOK {
+			t.Fatalf("update: status %d: %s", rec.Code, rec.Body)
+		}
+		if rec := env.do(t, http.MethodPut, "/api/v1/song", `{"id":2,"link":"https://muse.mu","reason":"официальный сайт"}`, header); rec.Code != http.StatusOK {
+			t.Fatalf("update: status %d: %s", rec.Code, rec.Body)
+		}
+		if rec := env.do(t, http.MethodDelete, "/api/v1/song/2/verses/5", "", nil); rec.Code != http.StatusOK {
+			t.Fatalf("delete verse: status %d: %s", rec.Code, rec.Body)
+		}
+
+		wantRevision(2, "editor@example.com", "опечатка в жанре")(t, env.do(t, http.MethodGet, "/api/v1/song/2/revisions/2", "", nil))
+		wantRevision(3, "editor@example.com", "официальный сайт")(t, env.do(t, http.MethodGet, "/api/v1/song/2/revisions/3", "", nil))
+		wantRevision(4, handler.ActorAnonymous, "")(t, env.do(t, http.MethodGet, "/api/v1/song/2/revisions/4", "", nil))
+
+		var revisions []model.SongRevision
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/2/revisions", "", nil), &revisions)
+		if len(revisions) != 4 || revisions[0].Revision != 4 || revisions[0].Text != "It's bugging me\n\nAnd twisting me around" {
+			t.Fatalf("revisions = %+v", revisions)
+		}
+		if revisions[1].Genre != "alternative rock" || revisions[1].Link != "https://muse.mu" {
+			t.Fatalf("revision 3 = %+v", revisions[1])
+		}
+	})
+
+	t.Run("diff shows changed fields and text lines", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodPut, "/api/v1/song", `{"id":3,"genre":"opera rock","text":"Is this the real life?\n\nCaught in a landslide"}`, nil)
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/3/revisions/diff?from=1&to=2", "", nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+
+		var diff dto.RevisionDiff
+		decode(t, rec, &diff)
+
+		if diff.SongId != 3 || diff.From != 1 || diff.To != 2 || len(diff.Changes) != 2 {
+			t.Fatalf("diff = %+v", diff)
+		}
+		if genre := diff.Changes[0]; genre.Field != "genre" || genre.Old != "rock" || genre.New != "opera rock" || genre.Lines != nil {
+			t.Fatalf("genre change = %+v", genre)
+		}
+
+		want := []linediff.Edit{
+			{Op: linediff.Equal, Text: "Is this the real life?"},
+			{Op: linediff.Equal, Text: ""},
+			{Op: linediff.Delete, Text: "Is this just fantasy?"},
+			{Op: linediff.Insert, Text: "Caught in a landslide"},
+		}
+		if text := diff.Changes[1]; text.Field != model.FieldText || !reflect.DeepEqual(text.Lines, want) {
+			t.Fatalf("text change = %+v", text)
+		}
+	})
+
+	t.Run("restore writes a new revision", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodPut, "/api/v1/song", `{"id":4,"song":"Кукушка","group":"Radiohead","text":"Песен еще ненаписанных"}`, nil)
+
+		rec := env.do(t, http.MethodPost, "/api/v1/song/4/revisions/1/restore", `{"reason":"ошибочная правка"}`, http.Header{"X-Actor": {"moderator"}})
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+		wantHeader("Location", "/api/v1/song/4/revisions/3")(t, rec)
+		wantRevision(3, "moderator", "восстановление ревизии 1: ошибочная правка")(t, rec)
+
+		var song model.Song
+		decode(t, env.do(t, http.MethodGet, "/api/v1/song/4", "", nil), &song)
+		if song.Song != "Группа крови" || song.Group != "Кино" || song.ReleaseDate != "01.01.1988" || song.Text != "Теплое место, но улицы ждут\n\nОтпечатков наших ног" {
+			t.Fatalf("restored song = %+v", song)
+		}
+
+		rec = env.do(t, http.MethodGet, "/api/v1/song/4/revisions/diff?from=1&to=3", "", nil)
+		if !strings.Contains(rec.Body.String(), `"changes":[]`) {
+			t.Fatalf("diff after restore: %s", rec.Body)
+		}
+	})
+
+	t.Run("history goes away with the song", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodDelete, "/api/v1/song/1", "", nil)
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/1/revisions", "", nil)
+		assertProblem(t, rec, http.StatusNotFound)
+	})
+}
+
+func TestGroups(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "list sorted by name", method: http.MethodGet, target: "/api/v1/groups", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var groups []model.Group
+			decode(t, rec, &groups)
+
+			var names []string
+			for _, group := range groups {
+				names = append(names, group.Name)
+			}
+			if strings.Join(names, ",") != "Muse,Queen,Radiohead,Кино" {
+				t.Fatalf("groups = %v", names)
+			}
+		}},
+		{name: "get by id", method: http.MethodGet, target: "/api/v1/groups/2", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var group model.Group
+			decode(t, rec, &group)
+
+			if group.ID != 2 || group.Name != "Queen" {
+				t.Fatalf("group = %+v", group)
+			}
+		}},
+		{name: "get unknown", method: http.MethodGet, target: "/api/v1/groups/99", wantStatus: http.StatusNotFound},
+		{name: "get invalid id", method: http.MethodGet, target: "/api/v1/groups/abc", wantStatus: http.StatusBadRequest},
+		{name: "songs of group", method: http.MethodGet, target: "/api/v1/groups/1/songs", wantStatus: http.StatusOK, check: wantSongIDs(1, 2)},
+		{name: "songs of empty group", method: http.MethodGet, target: "/api/v1/groups/4/songs", wantStatus: http.StatusOK, check: wantSongIDs()},
+		{name: "songs of unknown group", method: http.MethodGet, target: "/api/v1/groups/99/songs", wantStatus: http.StatusNotFound},
+		{name: "songs of invalid id", method: http.MethodGet, target: "/api/v1/groups/abc/songs", wantStatus: http.StatusBadRequest},
+		{name: "add", method: http.MethodPost, target: "/api/v1/groups", body: `{"name":"Blur"}`, wantStatus: http.StatusCreated, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var body struct {
+				Id int `json:"id"`
+			}
+			decode(t, rec, &body)
+
+			if body.Id != 5 {
+				t.Fatalf("id = %d, want 5", body.Id)
+			}
+		}},
+		{name: "add duplicate", method: http.MethodPost, target: "/api/v1/groups", body: `{"name":"Muse"}`, wantStatus: http.StatusConflict},
+		{name: "add duplicate in other case", method: http.MethodPost, target: "/api/v1/groups", body: `{"name":"MUSE"}`, wantStatus: http.StatusConflict},
+		{name: "add blank name", method: http.MethodPost, target: "/api/v1/groups", body: `{"name":"  "}`, wantStatus: http.StatusBadRequest},
+		{name: "add without name", method: http.MethodPost, target: "/api/v1/groups", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "rename", method: http.MethodPut, target: "/api/v1/groups/4", body: `{"name":"Radiohead UK"}`, wantStatus: http.StatusOK},
+		{name: "rename to own name", method: http.MethodPut, target: "/api/v1/groups/4", body: `{"name":"Radiohead"}`, wantStatus: http.StatusOK},
+		{name: "rename to taken name", method: http.MethodPut, target: "/api/v1/groups/4", body: `{"name":"Queen"}`, wantStatus: http.StatusConflict},
+		{name: "rename to taken name in other case", method: http.MethodPut, target: "/api/v1/groups/4", body: `{"name":"queen"}`, wantStatus: http.StatusConflict},
+		{name: "rename unknown", method: http.MethodPut, target: "/api/v1/groups/99", body: `{"name":"Blur"}`, wantStatus: http.StatusNotFound},
+		{name: "rename invalid id", method: http.MethodPut, target: "/api/v1/groups/abc", body: `{"name":"Blur"}`, wantStatus: http.StatusBadRequest},
+		{name: "rename without name", method: http.MethodPut, target: "/api/v1/groups/4", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "delete empty group", method: http.MethodDelete, target: "/api/v1/groups/4", wantStatus: http.StatusOK},
+		{name: "delete group with songs", method: http.MethodDelete, target: "/api/v1/groups/1", wantStatus: http.StatusConflict},
+		{name: "delete unknown", method: http.MethodDelete, target: "/api/v1/groups/99", wantStatus: http.StatusNotFound},
+		{name: "delete invalid id", method: http.MethodDelete, target: "/api/v1/groups/abc", wantStatus: http.StatusBadRequest},
+		{name: "merge dry run", method: http.MethodPost, target: "/api/v1/groups/merge", body: `{"targetId":4,"sourceIds":[1,1],"dryRun":true}`, wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var result dto.MergeGroupsResult
+			decode(t, rec, &result)
+
+			if !result.DryRun || result.MovedSongs != 2 || len(result.SourceIds) != 1 {
+				t.Fatalf("result = %+v", result)
+			}
+			assertIDs(t, songIDs(result.Songs), []int{1, 2})
+		}},
+		{name: "merge unknown target", method: http.MethodPost, target: "/api/v1/groups/merge", body: `{"targetId":99,"sourceIds":[1]}`, wantStatus: http.StatusNotFound},
+		{name: "merge unknown source", method: http.MethodPost, target: "/api/v1/groups/merge", body: `{"targetId":1,"sourceIds":[2,99]}`, wantStatus: http.StatusNotFound},
+		{name: "merge into itself", method: http.MethodPost, target: "/api/v1/groups/merge", body: `{"targetId":1,"sourceIds":[1]}`, wantStatus: http.StatusBadRequest},
+		{name: "merge without sources", method: http.MethodPost, target: "/api/v1/groups/merge", body: `{"targetId":1,"sourceIds":[]}`, wantStatus: http.StatusBadRequest},
+	})
+
+	t.Run("song joins group regardless of case", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		body := `{"group":"MUSE","song":"Uprising","genre":"rock","releaseDate":"2009-09-07","text":"Paranoia is in bloom","link":"https://example.com/uprising"}`
+		if rec := env.do(t, http.MethodPost, "/api/v1/song", body, nil); rec.Code != http.StatusAccepted {
+			t.Fatalf("add status %d: %s", rec.Code, rec.Body)
+		}
+
+		wantSongIDs(1, 2, 6)(t, env.do(t, http.MethodGet, "/api/v1/groups/1/songs", "", nil))
+		if rec := env.do(t, http.MethodGet, "/api/v1/groups/5", "", nil); rec.Code != http.StatusNotFound {
+			t.Fatalf("new group created: status %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("merge moves songs and removes sources", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		rec := env.do(t, http.MethodPost, "/api/v1/groups/merge", `{"targetId":4,"sourceIds":[1,3]}`, nil)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status %d: %s", rec.Code, rec.Body)
+		}
+
+		wantSongIDs(1, 2, 4)(t, env.do(t, http.MethodGet, "/api/v1/groups/4/songs", "", nil))
+		for _, id := range []string{"1", "3"} {
+			if rec := env.do(t, http.MethodGet, "/api/v1/groups/"+id, "", nil); rec.Code != http.StatusNotFound {
+				t.Fatalf("source group %s: status %d, want 404", id, rec.Code)
+			}
+		}
+	})
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		env.do(t, http.MethodPost, "/api/v1/groups/merge", `{"targetId":4,"sourceIds":[1],"dryRun":true}`, nil)
+
+		wantSongIDs(1, 2)(t, env.do(t, http.MethodGet, "/api/v1/groups/1/songs", "", nil))
+		wantRevision(1, dto.ActorSystem, "")(t, env.do(t, http.MethodGet, "/api/v1/song/1/revisions/1", "", nil))
+		assertProblem(t, env.do(t, http.MethodGet, "/api/v1/song/1/revisions/2", "", nil), http.StatusNotFound)
+	})
+
+	t.Run("rename and merge are recorded in song history", func(t *testing.T) {
+		env := newTestEnv(t)
+
+		etag := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil).Header().Get("ETag")
+		header := http.Header{"X-Actor": {"editor@example.com"}, "X-Change-Reason": {"официальное написание"}}
+		if rec := env.do(t, http.MethodPut, "/api/v1/groups/1", `{"name":"MUSE"}`, header); rec.Code != http.StatusOK {
+			t.Fatalf("rename: status %d: %s", rec.Code, rec.Body)
+		}
+		if got := env.do(t, http.MethodGet, "/api/v1/song/1", "", nil).Header().Get("ETag"); got == etag {
+			t.Fatalf("rename kept song version %s", got)
+		}
+
+		for _, id := range []string{"1", "2"} {
+			rec := env.do(t, http.MethodGet, "/api/v1/song/"+id+"/revisions/2", "", nil)
+			wantRevision(2, "editor@example.com", `группа переименована в "MUSE": официальное написание`)(t, rec)
+
+			var revision model.SongRevision
+			decode(t, rec, &revision)
+			if revision.Group != "MUSE" {
+				t.Fatalf("song %s: revision group = %q, want MUSE", id, revision.Group)
+			}
+		}
+
+		// Переименование без изменения имени ничего не записывает
+		env.do(t, http.MethodPut, "/api/v1/groups/1", `{"name":"MUSE"}`, nil)
+		assertProblem(t, env.do(t, http.MethodGet, "/api/v1/song/1/revisions/3", "", nil), http.StatusNotFound)
+
+		if rec := env.do(t, http.MethodPost, "/api/v1/groups/merge", `{"targetId":2,"sourceIds":[1]}`, nil); rec.Code != http.StatusOK {
+			t.Fatalf("merge: status %d: %s", rec.Code, rec.Body)
+		}
+
+		rec := env.do(t, http.MethodGet, "/api/v1/song/2/revisions/3", "", nil)
+		wantRevision(3, handler.ActorAnonymous, "группы [1] объединены в группу 2")(t, rec)
+
+		var revision model.SongRevision
+		decode(t, rec, &revision)
+		if revision.Group != "Queen" {
+			t.Fatalf("revision group = %q, want Queen", revision.Group)
+		}
+		assertProblem(t, env.do(t, http.MethodGet, "/api/v1/song/3/revisions/2", "", nil), http.StatusNotFound)
+	})
+}
+
+func TestSwagger(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "ui", method: http.MethodGet, target: "/swagger/index.html", wantStatus: http.StatusOK},
+		{name: "spec", method: http.MethodGet, target: "/swagger/doc.json", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			if !strings.Contains(rec.Body.String(), "/api/v1/songs") {
+				t.Fatal("spec does not describe /api/v1/songs")
+			}
+		}},
+	})
+}
+
+func TestDebugVars(t *testing.T) {
+	runRouteTests(t, []routeTest{
+		{name: "expvar", method: http.MethodGet, target: "/debug/vars", wantStatus: http.StatusOK, check: func(t *testing.T, rec *httptest.ResponseRecorder) {
+			var vars map[string]json.RawMessage
+			decode(t, rec, &vars)
+
+			if _, ok := vars["memstats"]; !ok {
+				t.Fatalf("vars = %s", rec.Body)
+			}
+		}},
+	})
+}
+
+func TestUnknownRoute(t *testing.T) {
+	env := newTestEnv(t)
+
+	if rec := env.do(t, http.MethodGet, "/api/v1/albums", "", nil); rec.Code != http.StatusNotFound {
+		t.Fatalf("status %d, want 404", rec.Code)
+	}
+}
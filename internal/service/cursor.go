@@ -0,0 +1,53 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+var ErrInvalidCursor = errs.Validation("некорректный курсор пагинации")
+
+// newSongsCursor запоминает ключи сортировки песни, после которой начнется следующая страница
+func newSongsCursor(song model.Song, sortBy []dto.SortField) dto.SongsCursor {
+	cursor := dto.SongsCursor{Sort: sortSpec(sortBy)}
+	for _, field := range sortBy {
+		cursor.Values = append(cursor.Values, songSortValue(song, field.Field))
+	}
+
+	return cursor
+}
+
+// encodeSongsCursor упаковывает позицию выборки в непрозрачный для клиента токен
+func encodeSongsCursor(cursor dto.SongsCursor) (string, error) {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeSongsCursor распаковывает токен и проверяет, что он выдан для той же сортировки
+func decodeSongsCursor(token string, sortBy []dto.SortField) (dto.SongsCursor, error) {
+	var cursor dto.SongsCursor
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return dto.SongsCursor{}, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(data, &cursor); err != nil || len(cursor.Values) == 0 {
+		return dto.SongsCursor{}, ErrInvalidCursor
+	}
+
+	if cursor.Sort != sortSpec(sortBy) || len(cursor.Values) != len(sortBy) {
+		return dto.SongsCursor{}, fmt.Errorf("%w: курсор выдан для другой сортировки", ErrInvalidCursor)
+	}
+
+	return cursor, nil
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+// EnrichmentPolicy настройки фонового дополнения песен данными внешнего API
+type EnrichmentPolicy struct {
+	// BatchSize число песен, забираемых из очереди за один проход
+	BatchSize int
+	// MaxAttempts число попыток, после которого песня получает статус failed
+	MaxAttempts int
+	// RetryBaseDelay и RetryMaxDelay задают экспоненциальную задержку между попытками
+	RetryBaseDelay time.Duration
+	RetryMaxDelay  time.Duration
+	// Lease время, на которое взятая песня скрывается из очереди
+	Lease time.Duration
+}
+
+// EnrichmentPolicyFromEnv читает настройки из переменных окружения ENRICHMENT_*
+func EnrichmentPolicyFromEnv() EnrichmentPolicy {
+	policy := EnrichmentPolicy{
+		BatchSize:      10,
+		MaxAttempts:    5,
+		RetryBaseDelay: 30 * time.Second,
+		RetryMaxDelay:  time.Hour,
+		Lease:          5 * time.Minute,
+	}
+
+	if value, err := strconv.Atoi(os.Getenv("ENRICHMENT_BATCH_SIZE")); err == nil && value > 0 {
+		policy.BatchSize = value
+	}
+	if value, err := strconv.Atoi(os.Getenv("ENRICHMENT_MAX_ATTEMPTS")); err == nil && value > 0 {
+		policy.MaxAttempts = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("ENRICHMENT_RETRY_BASE_DELAY")); err == nil && value > 0 {
+		policy.RetryBaseDelay = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("ENRICHMENT_RETRY_MAX_DELAY")); err == nil && value > 0 {
+		policy.RetryMaxDelay = value
+	}
+	if value, err := time.ParseDuration(os.Getenv("ENRICHMENT_LEASE")); err == nil && value > 0 {
+		policy.Lease = value
+	}
+
+	return policy
+}
+
+type EnrichmentService struct {
+	repo        *repository.Repository
+	externalAPI ExternalAPI
+	policy      EnrichmentPolicy
+	logger      *logging.Logger
+}
+
+func NewEnrichmentService(repo *repository.Repository, externalAPI ExternalAPI, policy EnrichmentPolicy) *EnrichmentService {
+	return &EnrichmentService{
+		repo:        repo,
+		externalAPI: externalAPI,
+		policy:      policy,
+		logger:      logging.GetLogger(),
+	}
+}
+
+// RequestEnrichment ставит песню в очередь на дополнение заново. Пустая policy
+// оставляет политику, заданную при добавлении (never при этом заменяется на missing).
+// Версия песни растет при каждом запросе, поэтому уже начатое дополнение со старой
+// политикой не сохранится и песня будет дополнена заново
+func (s EnrichmentService) RequestEnrichment(ctx context.Context, id int, policy string) error {
+	switch policy {
+	case "", model.EnrichMissing, model.EnrichAlways:
+	case model.EnrichNever:
+		return errs.Validation("политика %s не допускает дополнения", policy)
+	default:
+		return errs.Validation("неизвестная политика дополнения: %s", policy)
+	}
+
+	return s.repo.RequestEnrichment(ctx, id, policy)
+}
+
+// ProcessPendingEnrichments дополняет очередную пачку песен из очереди и возвращает их число.
+// Неудачные попытки повторяются с экспоненциальной задержкой, пока не исчерпан MaxAttempts.
+// Если внешний API не знает песню, повторять бессмысленно: песня сразу получает статус failed.
+// Ошибка одной песни пишется в лог и не мешает остальным песням пачки: иначе они оставались бы
+// скрытыми из очереди до истечения Lease
+func (s EnrichmentService) ProcessPendingEnrichments(ctx context.Context) (int, error) {
+	tasks, err := s.repo.ClaimSongsForEnrichment(ctx, s.policy.BatchSize, s.policy.Lease)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, task := range tasks {
+		if err := s.enrich(ctx, task); err != nil {
+			if ctx.Err() != nil {
+				// Оставшиеся песни вернутся в очередь по истечении Lease
+				return 0, ctx.Err()
+			}
+			s.logger.Errorf("Ошибка при дополнении песни %d: %v", task.SongID, err)
+		}
+	}
+
+	return len(tasks), nil
+}
+
+func (s EnrichmentService) enrich(ctx context.Context, task model.EnrichmentTask) error {
+	details, err := s.externalAPI.GetSongDetails(ctx, task.Group, task.Song)
+	if err == nil {
+		details.ReleaseDate, err = normalizeReleaseDate(details.ReleaseDate)
+		details.Text = dto.NormalizeText(details.Text)
+	}
+
+	// Неполные данные резервных поставщиков используются только на последней попытке,
+	// до этого дополнение повторяется в расчете на восстановление основного поставщика
+	if err == nil && details.Partial && task.Attempts < s.policy.MaxAttempts {
+		err = errs.UpstreamUnavailable(nil, "часть поставщиков метаданных недоступна")
+	}
+
+	if ctx.Err() != nil {
+		// Песня вернется в очередь по истечении Lease
+		return ctx.Err()
+	}
+
+	if err == nil {
+		fields := mergeSongDetails(task, details)
+		s.logger.Infof("Песня %d дополнена данными внешнего API, заполнены поля: %v", task.SongID, fields)
+
+		ctx = dto.WithChange(ctx, dto.Change{
+			Actor:  dto.ActorSystem,
+			Reason: "дополнение данными внешнего API: " + strings.Join(fields, ", "),
+		})
+		err = s.repo.CompleteEnrichment(ctx, task.SongID, task.Version, *details, fields)
+		if errs.KindOf(err) == errs.KindPreconditionFailed {
+			// Клиент изменил песню во время запроса: она уже вернулась в очередь и будет
+			// дополнена по новому состоянию
+			s.logger.Infof("Песня %d изменена во время дополнения, дополнение повторится: %v", task.SongID, err)
+			return nil
+		}
+		return err
+	}
+
+	if errs.KindOf(err) == errs.KindValidation || task.Attempts >= s.policy.MaxAttempts {
+		s.logger.Errorf("Песню %d не удалось дополнить (попытка %d): %v", task.SongID, task.Attempts, err)
+		return s.repo.FailEnrichment(ctx, task.SongID, err.Error(), nil)
+	}
+
+	retryAt := time.Now().Add(s.retryDelay(task.Attempts))
+	s.logger.Warnf("Песню %d не удалось дополнить (попытка %d из %d), повтор в %s: %v",
+		task.SongID, task.Attempts, s.policy.MaxAttempts, retryAt.Format(time.RFC3339), err)
+
+	return s.repo.FailEnrichment(ctx, task.SongID, err.Error(), &retryAt)
+}
+
+// mergeSongDetails возвращает поля, которые по политике task.Policy берутся из внешнего API:
+// при missing — только пустые у песни, при always — любые. Пустые значения внешнего API не используются
+func mergeSongDetails(task model.EnrichmentTask, details *dto.SongDetails) []string {
+	candidates := []struct {
+		field    string
+		current  string
+		upstream string
+	}{
+		{model.FieldReleaseDate, task.ReleaseDate, details.ReleaseDate},
+		{model.FieldText, task.Text, details.Text},
+		{model.FieldLink, task.Link, details.Link},
+	}
+
+	fields := []string{}
+	for _, candidate := range candidates {
+		if candidate.upstream == "" || candidate.upstream == candidate.current {
+			continue
+		}
+		if task.Policy == model.EnrichAlways || candidate.current == "" {
+			fields = append(fields, candidate.field)
+		}
+	}
+
+	return fields
+}
+
+// retryDelay задержка перед попыткой attempts+1: RetryBaseDelay * 2^(attempts-1), но не больше RetryMaxDelay
+func (s EnrichmentService) retryDelay(attempts int) time.Duration {
+	delay := s.policy.RetryBaseDelay
+	for i := 1; i < attempts && delay < s.policy.RetryMaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > s.policy.RetryMaxDelay {
+		delay = s.policy.RetryMaxDelay
+	}
+
+	return delay
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/fakeinfo"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/memrepo"
+)
+
+func TestMain(m *testing.M) {
+	logging.InitNop()
+
+	os.Exit(m.Run())
+}
+
+// failingEnrichment отказывает в сохранении дополнения песни failId
+type failingEnrichment struct {
+	repository.Enrichment
+	failId int
+}
+
+func (f failingEnrichment) CompleteEnrichment(ctx context.Context, id, version int, details dto.SongDetails, fields []string) error {
+	if id == f.failId {
+		return errors.New("connection reset by peer")
+	}
+	return f.Enrichment.CompleteEnrichment(ctx, id, version, details, fields)
+}
+
+func TestProcessPendingEnrichmentsContinuesAfterError(t *testing.T) {
+	ctx := context.Background()
+	repo := memrepo.NewRepository()
+	api := fakeinfo.NewAPI()
+
+	for _, song := range []string{"Hysteria", "Uprising"} {
+		if _, err := repo.AddSong(ctx, dto.AddSongRequest{Group: "Muse", Song: song, Enrich: model.EnrichMissing}, model.EnrichmentPending); err != nil {
+			t.Fatalf("AddSong(%s): %v", song, err)
+		}
+		api.SetSongDetails("Muse", song, dto.SongDetails{Link: "https://example.com/" + song})
+	}
+	repo.Enrichment = failingEnrichment{Enrichment: repo.Enrichment, failId: 1}
+
+	policy := EnrichmentPolicy{BatchSize: 10, MaxAttempts: 5, RetryBaseDelay: time.Second, RetryMaxDelay: time.Minute, Lease: time.Minute}
+	processed, err := NewEnrichmentService(repo, api, policy).ProcessPendingEnrichments(ctx)
+	if err != nil || processed != 2 {
+		t.Fatalf("ProcessPendingEnrichments = %d, %v, want 2 without error", processed, err)
+	}
+
+	song, err := repo.GetSongById(ctx, 2)
+	if err != nil {
+		t.Fatalf("GetSongById: %v", err)
+	}
+	if song.EnrichmentStatus != model.EnrichmentEnriched || song.Link != "https://example.com/Uprising" {
+		t.Fatalf("song after failed neighbour = %+v", song)
+	}
+}
+
+func TestEnrichmentPolicyFromEnvLease(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{value: "", want: 5 * time.Minute},
+		{value: "90s", want: 90 * time.Second},
+		{value: "0s", want: 5 * time.Minute},
+		{value: "soon", want: 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("ENRICHMENT_LEASE", tt.value)
+
+		if got := EnrichmentPolicyFromEnv().Lease; got != tt.want {
+			t.Errorf("ENRICHMENT_LEASE=%q: lease = %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+// Package errs описывает доменные ошибки сервиса. Репозиторий и внешние клиенты
+// переводят в них ошибки драйвера и HTTP, а обработчики по виду ошибки выбирают HTTP-статус
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kind вид доменной ошибки
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindNotFound
+	KindConflict
+	KindValidation
+	KindUpstreamUnavailable
+	KindPreconditionFailed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindNotFound:
+		return "not found"
+	case KindConflict:
+		return "conflict"
+	case KindValidation:
+		return "validation"
+	case KindUpstreamUnavailable:
+		return "upstream unavailable"
+	case KindPreconditionFailed:
+		return "precondition failed"
+	default:
+		return "internal"
+	}
+}
+
+// Error доменная ошибка с видом, сообщением для клиента и исходной причиной
+type Error struct {
+	Kind Kind
+	// Message сообщение для клиента, попадает в detail ответа
+	Message string
+	// Err исходная причина, только для логов: текст драйвера и внешних сервисов клиенту не отдается
+	Err error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func newError(kind Kind, err error, format string, args ...interface{}) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Err: err}
+}
+
+// NotFound запрошенный объект не существует
+func NotFound(format string, args ...interface{}) error {
+	return newError(KindNotFound, nil, format, args...)
+}
+
+// Conflict операция противоречит текущему состоянию данных
+func Conflict(format string, args ...interface{}) error {
+	return newError(KindConflict, nil, format, args...)
+}
+
+// Validation некорректные входные данные
+func Validation(format string, args ...interface{}) error {
+	return newError(KindValidation, nil, format, args...)
+}
+
+// PreconditionFailed объект изменился с тех пор, как клиент его получил
+func PreconditionFailed(format string, args ...interface{}) error {
+	return newError(KindPreconditionFailed, nil, format, args...)
+}
+
+// UpstreamUnavailable внешний сервис недоступен или ответил ошибкой
+func UpstreamUnavailable(err error, format string, args ...interface{}) error {
+	return newError(KindUpstreamUnavailable, err, format, args...)
+}
+
+// Wrap оборачивает причину err в доменную ошибку вида kind
+func Wrap(kind Kind, err error, format string, args ...interface{}) error {
+	return newError(kind, err, format, args...)
+}
+
+// KindOf возвращает вид доменной ошибки в цепочке err, для прочих ошибок — KindInternal
+func KindOf(err error) Kind {
+	var domainErr *Error
+	if errors.As(err, &domainErr) {
+		return domainErr.Kind
+	}
+	return KindInternal
+}
+
+// Is сообщает, что в цепочке err есть доменная ошибка вида kind
+func Is(err error, kind Kind) bool {
+	return err != nil && KindOf(err) == kind
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type GroupsService struct {
+	repo *repository.Repository
+}
+
+func NewGroupsService(repo *repository.Repository) *GroupsService {
+	return &GroupsService{
+		repo: repo,
+	}
+}
+
+func (s GroupsService) GetGroups(ctx context.Context) ([]model.Group, error) {
+	return s.repo.GetGroups(ctx)
+}
+
+func (s GroupsService) GetGroupById(ctx context.Context, id int) (model.Group, error) {
+	return s.repo.GetGroupById(ctx, id)
+}
+
+func (s GroupsService) AddGroup(ctx context.Context, req dto.AddGroupRequest) (int, error) {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return 0, errs.Validation("название группы не может быть пустым")
+	}
+
+	return s.repo.AddGroup(ctx, req)
+}
+
+func (s GroupsService) UpdateGroup(ctx context.Context, req dto.UpdateGroupRequest) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errs.Validation("название группы не может быть пустым")
+	}
+
+	return s.repo.UpdateGroup(withGroupChangeReason(ctx, fmt.Sprintf("группа переименована в %q", req.Name)), req)
+}
+
+func (s GroupsService) DeleteGroup(ctx context.Context, id int) error {
+	return s.repo.DeleteGroup(ctx, id)
+}
+
+func (s GroupsService) GetGroupSongs(ctx context.Context, id int) ([]model.Song, error) {
+	if _, err := s.GetGroupById(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSongs(ctx, dto.GetSongsRequest{GroupId: id})
+}
+
+func (s GroupsService) MergeGroups(ctx context.Context, req dto.MergeGroupsRequest) (dto.MergeGroupsResult, error) {
+	if len(req.SourceIds) == 0 {
+		return dto.MergeGroupsResult{}, errs.Validation("не указаны исходные группы")
+	}
+
+	seen := make(map[int]struct{}, len(req.SourceIds))
+	sourceIds := make([]int, 0, len(req.SourceIds))
+	for _, id := range req.SourceIds {
+		if id == req.TargetId {
+			return dto.MergeGroupsResult{}, errs.Validation("группа %d не может быть одновременно исходной и целевой", id)
+		}
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		sourceIds = append(sourceIds, id)
+	}
+	req.SourceIds = sourceIds
+
+	return s.repo.MergeGroups(withGroupChangeReason(ctx, fmt.Sprintf("группы %v объединены в группу %d", req.SourceIds, req.TargetId)), req)
+}
+
+// withGroupChangeReason задает причину ревизий песен, которые меняются вместе с группой.
+// Причина, переданная клиентом, дополняет ее
+func withGroupChangeReason(ctx context.Context, reason string) context.Context {
+	if clientReason := dto.ChangeFrom(ctx).Reason; clientReason != "" {
+		reason += ": " + clientReason
+	}
+
+	return dto.WithReason(ctx, reason)
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/linediff"
+)
+
+type RevisionsService struct {
+	repo *repository.Repository
+}
+
+func NewRevisionsService(repo *repository.Repository) *RevisionsService {
+	return &RevisionsService{
+		repo: repo,
+	}
+}
+
+func (s RevisionsService) GetRevisions(ctx context.Context, songId int) ([]model.SongRevision, error) {
+	return s.repo.GetRevisions(ctx, songId)
+}
+
+func (s RevisionsService) GetRevision(ctx context.Context, songId, revision int) (model.SongRevision, error) {
+	return s.repo.GetRevision(ctx, songId, revision)
+}
+
+// DiffRevisions сравнивает поля двух ревизий песни. Текст сравнивается построчно
+func (s RevisionsService) DiffRevisions(ctx context.Context, songId, from, to int) (dto.RevisionDiff, error) {
+	before, err := s.repo.GetRevision(ctx, songId, from)
+	if err != nil {
+		return dto.RevisionDiff{}, err
+	}
+
+	after, err := s.repo.GetRevision(ctx, songId, to)
+	if err != nil {
+		return dto.RevisionDiff{}, err
+	}
+
+	diff := dto.RevisionDiff{SongId: songId, From: from, To: to, Changes: []dto.FieldChange{}}
+
+	fields := []struct {
+		name     string
+		old, new string
+	}{
+		{"song", before.Song, after.Song},
+		{"group", before.Group, after.Group},
+		{"genre", before.Genre, after.Genre},
+		{model.FieldReleaseDate, before.ReleaseDate, after.ReleaseDate},
+		{model.FieldText, before.Text, after.Text},
+		{model.FieldLink, before.Link, after.Link},
+	}
+
+	for _, field := range fields {
+		if field.old == field.new {
+			continue
+		}
+
+		change := dto.FieldChange{Field: field.name, Old: field.old, New: field.new}
+		if field.name == model.FieldText {
+			change.Lines = linediff.Lines(field.old, field.new)
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	return diff, nil
+}
+
+// RestoreRevision возвращает песне поля ревизии req.Revision. Восстановление записывается
+// новой ревизией, история не переписывается. Текст восстанавливается целиком, поэтому
+// куплеты получают тип verse
+func (s RevisionsService) RestoreRevision(ctx context.Context, req dto.RestoreRevisionRequest) (model.SongRevision, error) {
+	revision, err := s.repo.GetRevision(ctx, req.SongId, req.Revision)
+	if err != nil {
+		return model.SongRevision{}, err
+	}
+
+	releaseDate, err := normalizeReleaseDate(revision.ReleaseDate)
+	if err != nil {
+		return model.SongRevision{}, err
+	}
+
+	update := dto.UpdateSongRequest{
+		Id:          req.SongId,
+		Song:        &revision.Song,
+		Genre:       &revision.Genre,
+		ReleaseDate: &releaseDate,
+		Text:        &revision.Text,
+		Link:        &revision.Link,
+	}
+	if revision.Group != "" {
+		update.Group = &revision.Group
+	}
+
+	reason := fmt.Sprintf("восстановление ревизии %d", req.Revision)
+	if req.Reason != "" {
+		reason += ": " + req.Reason
+	}
+
+	if _, err := s.repo.UpdateSong(dto.WithReason(ctx, reason), update); err != nil {
+		return model.SongRevision{}, err
+	}
+
+	revisions, err := s.repo.GetRevisions(ctx, req.SongId)
+	if err != nil {
+		return model.SongRevision{}, err
+	}
+
+	return revisions[0], nil
+}
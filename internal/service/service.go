@@ -1,33 +1,104 @@
 package service
 
 import (
+	"context"
+	"time"
+
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
 )
 
 type Songs interface {
-	GetSongs(req dto.GetSongsRequest) ([]model.Song, error)
-	GetSongById(id int) (model.Song, error)
-	AddSong(song dto.AddSongRequest) (int, error)
-	UpdateSong(song dto.UpdateSongRequest) error
-	DeleteSong(id int) error
-	GetSongLyrics(req dto.GetSongLyricsRequest) ([]string, error)
-	SearchSongs(query string) ([]model.Song, error)
+	GetSongs(ctx context.Context, req dto.GetSongsRequest) ([]model.Song, error)
+	GetSongsPage(ctx context.Context, req dto.GetSongsRequest) (dto.SongsPage, error)
+	GetSongById(ctx context.Context, id int) (model.Song, error)
+	AddSong(ctx context.Context, song dto.AddSongRequest) (dto.AddSongResult, error)
+	UpdateSong(ctx context.Context, song dto.UpdateSongRequest) (int, error)
+	DeleteSong(ctx context.Context, id int, ifMatch dto.IfMatch) error
+	GetSongLyrics(ctx context.Context, req dto.GetSongLyricsRequest) (dto.SongLyrics, error)
+	SearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error)
+	SuggestSongs(ctx context.Context, req dto.SuggestRequest) ([]model.Suggestion, error)
+}
+
+type Groups interface {
+	GetGroups(ctx context.Context) ([]model.Group, error)
+	GetGroupById(ctx context.Context, id int) (model.Group, error)
+	AddGroup(ctx context.Context, group dto.AddGroupRequest) (int, error)
+	UpdateGroup(ctx context.Context, group dto.UpdateGroupRequest) error
+	DeleteGroup(ctx context.Context, id int) error
+	GetGroupSongs(ctx context.Context, id int) ([]model.Song, error)
+	MergeGroups(ctx context.Context, req dto.MergeGroupsRequest) (dto.MergeGroupsResult, error)
+}
+
+type Verses interface {
+	GetSongVerses(ctx context.Context, songId int) ([]model.Verse, error)
+	GetVerse(ctx context.Context, songId, verseId int) (model.Verse, error)
+	AddVerse(ctx context.Context, req dto.AddVerseRequest) (model.Verse, error)
+	UpdateVerse(ctx context.Context, req dto.UpdateVerseRequest) error
+	DeleteVerse(ctx context.Context, songId, verseId int) error
+	ReorderVerses(ctx context.Context, req dto.ReorderVersesRequest) ([]model.Verse, error)
+}
+
+type SyncedLyrics interface {
+	GetSyncedLyrics(ctx context.Context, songId int) (dto.SyncedLyrics, error)
+	GetSyncedLineAt(ctx context.Context, songId int, at time.Duration) (dto.SyncedLineAt, error)
+	ImportLRC(ctx context.Context, songId int, data string) (dto.SyncedLyrics, error)
+	ExportLRC(ctx context.Context, songId int) (string, error)
+	DeleteSyncedLyrics(ctx context.Context, songId int) error
+}
+
+type Translations interface {
+	GetTranslations(ctx context.Context, songId int) ([]model.Translation, error)
+	GetTranslation(ctx context.Context, songId int, lang string) (model.Translation, error)
+	SaveTranslation(ctx context.Context, req dto.SaveTranslationRequest) (model.Translation, bool, error)
+	DeleteTranslation(ctx context.Context, songId int, lang string) error
+}
+
+type Revisions interface {
+	GetRevisions(ctx context.Context, songId int) ([]model.SongRevision, error)
+	GetRevision(ctx context.Context, songId, revision int) (model.SongRevision, error)
+	DiffRevisions(ctx context.Context, songId, from, to int) (dto.RevisionDiff, error)
+	RestoreRevision(ctx context.Context, req dto.RestoreRevisionRequest) (model.SongRevision, error)
+}
+
+type Trash interface {
+	GetTrash(ctx context.Context) ([]dto.TrashedSong, error)
+	RestoreSong(ctx context.Context, id int) (model.Song, error)
+	PurgeTrash(ctx context.Context) (int, error)
+}
+
+type Enrichment interface {
+	RequestEnrichment(ctx context.Context, id int, policy string) error
+	ProcessPendingEnrichments(ctx context.Context) (int, error)
 }
 
 type ExternalAPI interface {
-	GetSongDetails(group, song string) (*dto.SongDetails, error)
+	GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error)
 }
 
 type Service struct {
 	Songs
+	Groups
+	Verses
+	SyncedLyrics
+	Translations
+	Revisions
+	Trash
+	Enrichment
 	externalAPI ExternalAPI
 }
 
 func NewService(repo *repository.Repository, externalAPI ExternalAPI) *Service {
 	return &Service{
-		Songs:       NewSongsService(repo, externalAPI),
-		externalAPI: externalAPI,
+		Songs:        NewSongsService(repo),
+		Groups:       NewGroupsService(repo),
+		Verses:       NewVersesService(repo),
+		SyncedLyrics: NewSyncedLyricsService(repo),
+		Translations: NewTranslationsService(repo),
+		Revisions:    NewRevisionsService(repo),
+		Trash:        NewTrashService(repo, TrashRetentionFromEnv()),
+		Enrichment:   NewEnrichmentService(repo, externalAPI, EnrichmentPolicyFromEnv()),
+		externalAPI:  externalAPI,
 	}
 }
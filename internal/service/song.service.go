@@ -1,67 +1,265 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"strings"
 
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
 	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
 )
 
 type SongsService struct {
-	repo        *repository.Repository
-	externalAPI ExternalAPI
+	repo *repository.Repository
 }
 
-func NewSongsService(repo *repository.Repository, externalAPI ExternalAPI) *SongsService {
+func NewSongsService(repo *repository.Repository) *SongsService {
 	return &SongsService{
-		repo:        repo,
-		externalAPI: externalAPI,
+		repo: repo,
 	}
 }
 
-func (s SongsService) GetSongs(req dto.GetSongsRequest) ([]model.Song, error) {
-	return s.repo.GetSongs(req)
+func (s SongsService) GetSongs(ctx context.Context, req dto.GetSongsRequest) ([]model.Song, error) {
+	sortBy, err := parseSongsSort(req.Sort)
+	if err != nil {
+		return nil, err
+	}
+	req.SortBy = withIDTieBreak(sortBy)
+
+	return s.repo.GetSongs(ctx, req)
 }
 
-func (s SongsService) GetSongById(id int) (model.Song, error) {
-	return s.repo.GetSongById(id)
+// normalizeReleaseDate приводит дату выхода в формате ISO-8601 или DD.MM.YYYY к ISO-8601.
+// Пустая дата остается пустой
+func normalizeReleaseDate(value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", nil
+	}
+
+	date, err := dto.ParseDate(value)
+	if err != nil {
+		return "", err
+	}
+
+	return date.Format(dto.ISODateLayout), nil
 }
 
-func (s SongsService) AddSong(req dto.AddSongRequest) (int, error) {
-	details, err := s.externalAPI.GetSongDetails(req.Group, req.Song)
+const defaultSongsPageSize = 50
+
+// GetSongsPage возвращает страницу песен для курсорной пагинации.
+// Следующая страница начинается строго после последней песни текущей,
+// поэтому вставки и удаления между запросами не сдвигают выдачу
+func (s SongsService) GetSongsPage(ctx context.Context, req dto.GetSongsRequest) (dto.SongsPage, error) {
+	if req.Limit == 0 {
+		req.Limit = defaultSongsPageSize
+	}
+
+	sortBy, err := parseSongsSort(req.Sort)
 	if err != nil {
-		return 0, err
+		return dto.SongsPage{}, err
+	}
+	req.SortBy = withIDTieBreak(sortBy)
+
+	if req.Cursor != "" {
+		after, err := decodeSongsCursor(req.Cursor, req.SortBy)
+		if err != nil {
+			return dto.SongsPage{}, err
+		}
+		req.After = &after
+	}
+
+	// Запрашиваем на одну песню больше, чтобы понять, есть ли следующая страница
+	limit := req.Limit
+	req.Limit = limit + 1
+	req.Offset = 0
+
+	songs, err := s.repo.GetSongs(ctx, req)
+	if err != nil {
+		return dto.SongsPage{}, err
+	}
+
+	page := dto.SongsPage{Songs: songs}
+	if page.Songs == nil {
+		page.Songs = []model.Song{}
 	}
 
-	req.ReleaseDate = details.ReleaseDate
-	req.Text = details.Text
-	req.Link = details.Link
+	if len(songs) > limit {
+		page.Songs = songs[:limit]
+
+		page.NextCursor, err = encodeSongsCursor(newSongsCursor(page.Songs[limit-1], req.SortBy))
+		if err != nil {
+			return dto.SongsPage{}, err
+		}
+	}
+
+	return page, nil
+}
 
-	return s.repo.AddSong(req)
+func (s SongsService) GetSongById(ctx context.Context, id int) (model.Song, error) {
+	return s.repo.GetSongById(ctx, id)
 }
 
-func (s SongsService) UpdateSong(req dto.UpdateSongRequest) error {
-	_, err := s.GetSongById(req.Id)
+// AddSong сохраняет песню сразу, не дожидаясь внешнего API: песня получает статус
+// pending и дополняется фоновым обработчиком (см. EnrichmentService). Если дополнять
+// нечего или клиент это запретил (enrich=never), песня получает статус skipped
+func (s SongsService) AddSong(ctx context.Context, req dto.AddSongRequest) (dto.AddSongResult, error) {
+	if req.Enrich == "" {
+		req.Enrich = model.EnrichMissing
+	}
+
+	var err error
+	if req.ReleaseDate, err = normalizeReleaseDate(req.ReleaseDate); err != nil {
+		return dto.AddSongResult{}, err
+	}
+	req.Text = dto.NormalizeText(req.Text)
+
+	status := model.EnrichmentPending
+	switch req.Enrich {
+	case model.EnrichNever:
+		status = model.EnrichmentSkipped
+	case model.EnrichMissing:
+		if req.ReleaseDate != "" && req.Text != "" && req.Link != "" {
+			status = model.EnrichmentSkipped
+		}
+	case model.EnrichAlways:
+	default:
+		return dto.AddSongResult{}, errs.Validation("неизвестная политика дополнения: %s", req.Enrich)
+	}
+
+	id, err := s.repo.AddSong(ctx, req, status)
 	if err != nil {
-		return err
+		return dto.AddSongResult{}, err
+	}
+
+	return dto.AddSongResult{Id: id, Enrich: req.Enrich, EnrichmentStatus: status}, nil
+}
+
+// UpdateSong изменяет песню и возвращает ее новую версию
+func (s SongsService) UpdateSong(ctx context.Context, req dto.UpdateSongRequest) (int, error) {
+	_, err := s.GetSongById(ctx, req.Id)
+	if err != nil {
+		return 0, err
+	}
+
+	if req.ReleaseDate != nil {
+		releaseDate, err := normalizeReleaseDate(*req.ReleaseDate)
+		if err != nil {
+			return 0, err
+		}
+		req.ReleaseDate = &releaseDate
 	}
 
-	return s.repo.UpdateSong(req)
+	if req.Text != nil {
+		text := dto.NormalizeText(*req.Text)
+		req.Text = &text
+	}
+
+	if req.Reason != "" {
+		ctx = dto.WithReason(ctx, req.Reason)
+	}
+
+	return s.repo.UpdateSong(ctx, req)
+}
+
+func (s SongsService) DeleteSong(ctx context.Context, id int, ifMatch dto.IfMatch) error {
+	return s.repo.DeleteSong(ctx, id, ifMatch)
 }
 
-func (s SongsService) DeleteSong(id int) error {
-	return s.repo.DeleteSong(id)
+// GetSongLyrics возвращает страницу куплетов песни. Страницы нумеруются с 1, по умолчанию
+// отдается первая страница из defaultVersesPageSize куплетов. Страница за пределами текста
+// пуста; has_more показывает, есть ли куплеты после текущей страницы. С req.Lang отдаются
+// куплеты перевода, а если их столько же, сколько в оригинале, то и куплеты оригинала рядом
+func (s SongsService) GetSongLyrics(ctx context.Context, req dto.GetSongLyricsRequest) (dto.SongLyrics, error) {
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultVersesPageSize
+	}
+
+	if req.Page < 1 {
+		return dto.SongLyrics{}, errs.Validation("номер страницы должен быть не меньше 1, получено %d", req.Page)
+	}
+	if req.Limit < 1 || req.Limit > maxVersesPageSize {
+		return dto.SongLyrics{}, errs.Validation("число куплетов на странице должно быть от 1 до %d, получено %d", maxVersesPageSize, req.Limit)
+	}
+
+	verses, err := s.repo.GetSongVerses(ctx, req.Id)
+	if err != nil {
+		return dto.SongLyrics{}, err
+	}
+
+	texts := make([]string, len(verses))
+	for i, verse := range verses {
+		texts[i] = verse.Text
+	}
+
+	if req.Lang == "" {
+		return paginateVerses(texts, req.Page, req.Limit), nil
+	}
+
+	translations, err := s.repo.GetTranslations(ctx, req.Id)
+	if err != nil {
+		return dto.SongLyrics{}, err
+	}
+
+	translation, err := matchTranslation(translations, req.Lang)
+	if err != nil {
+		return dto.SongLyrics{}, err
+	}
+
+	translated := dto.SplitVerses(translation.Text)
+	lyrics := paginateVerses(translated, req.Page, req.Limit)
+	lyrics.Lang = translation.Lang
+
+	// Куплеты сопоставляются по номеру, только если их число совпадает
+	if len(translated) == len(texts) {
+		lyrics.Original = paginateVerses(texts, req.Page, req.Limit).Verses
+	}
+
+	return lyrics, nil
 }
 
-func (s SongsService) GetSongLyrics(req dto.GetSongLyricsRequest) ([]string, error) {
-	return s.repo.GetSongLyrics(req)
+const (
+	defaultFuzzySearchLimit = 20
+	defaultSuggestLimit     = 10
+)
+
+func (s SongsService) SearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error) {
+	req.Query = strings.TrimSpace(req.Query)
+	if req.Query == "" {
+		return nil, errs.Validation("поисковый запрос не может быть пустым")
+	}
+
+	sortBy, err := parseSongsSort(req.Sort)
+	if err != nil {
+		return nil, err
+	}
+	req.SortBy = sortBy
+
+	switch req.Mode {
+	case "", dto.SearchModeFullText:
+		return s.repo.SearchSongs(ctx, req)
+	case dto.SearchModeFuzzy:
+		if req.Limit == 0 {
+			req.Limit = defaultFuzzySearchLimit
+		}
+		return s.repo.FuzzySearchSongs(ctx, req)
+	default:
+		return nil, errs.Validation("неизвестный режим поиска: %s", req.Mode)
+	}
 }
 
-func (s SongsService) SearchSongs(query string) ([]model.Song, error) {
-	if query == "" {
-		return nil, errors.New("поисковый запрос не может быть пустым")
+func (s SongsService) SuggestSongs(ctx context.Context, req dto.SuggestRequest) ([]model.Suggestion, error) {
+	req.Prefix = strings.TrimSpace(req.Prefix)
+	if req.Prefix == "" {
+		return nil, errs.Validation("префикс не может быть пустым")
+	}
+
+	if req.Limit == 0 {
+		req.Limit = defaultSuggestLimit
 	}
 
-	return s.repo.SearchSongs(query)
+	return s.repo.SuggestSongs(ctx, req.Prefix, req.Limit)
 }
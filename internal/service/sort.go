@@ -0,0 +1,93 @@
+package service
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+var ErrInvalidSort = errs.Validation("некорректный параметр сортировки")
+
+// songSortFields поля, по которым разрешена сортировка песен
+var songSortFields = map[string]bool{
+	"id":          true,
+	"song":        true,
+	"group":       true,
+	"genre":       true,
+	"releaseDate": true,
+}
+
+// parseSongsSort разбирает параметр вида "-releaseDate,group,song":
+// поля перечисляются через запятую, минус перед полем означает сортировку по убыванию
+func parseSongsSort(raw string) ([]dto.SortField, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	var fields []dto.SortField
+	seen := make(map[string]bool)
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+
+		field := dto.SortField{Field: strings.TrimLeft(part, "+-")}
+		field.Desc = strings.HasPrefix(part, "-")
+
+		if !songSortFields[field.Field] {
+			return nil, fmt.Errorf("%w: поле %q недоступно для сортировки", ErrInvalidSort, field.Field)
+		}
+		if seen[field.Field] {
+			return nil, fmt.Errorf("%w: поле %q указано дважды", ErrInvalidSort, field.Field)
+		}
+		seen[field.Field] = true
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// withIDTieBreak дополняет сортировку полем id, чтобы порядок песен был однозначным
+func withIDTieBreak(fields []dto.SortField) []dto.SortField {
+	for _, field := range fields {
+		if field.Field == "id" {
+			return fields
+		}
+	}
+
+	return append(fields, dto.SortField{Field: "id"})
+}
+
+// sortSpec возвращает каноническую запись сортировки
+func sortSpec(fields []dto.SortField) string {
+	parts := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field.Desc {
+			parts = append(parts, "-"+field.Field)
+		} else {
+			parts = append(parts, field.Field)
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// songSortValue возвращает значение поля сортировки песни для курсора
+func songSortValue(song model.Song, field string) string {
+	switch field {
+	case "song":
+		return song.Song
+	case "group":
+		return song.Group
+	case "genre":
+		return song.Genre
+	case "releaseDate":
+		return song.ReleaseDate
+	default:
+		return strconv.Itoa(song.ID)
+	}
+}
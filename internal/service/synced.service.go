@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/lrc"
+)
+
+type SyncedLyricsService struct {
+	repo *repository.Repository
+}
+
+func NewSyncedLyricsService(repo *repository.Repository) *SyncedLyricsService {
+	return &SyncedLyricsService{
+		repo: repo,
+	}
+}
+
+func (s SyncedLyricsService) GetSyncedLyrics(ctx context.Context, songId int) (dto.SyncedLyrics, error) {
+	lines, err := s.syncedLines(ctx, songId)
+	if err != nil {
+		return dto.SyncedLyrics{}, err
+	}
+
+	return syncedLyrics(songId, lines), nil
+}
+
+// GetSyncedLineAt возвращает строку, которая звучит в момент at, и следующую за ней
+func (s SyncedLyricsService) GetSyncedLineAt(ctx context.Context, songId int, at time.Duration) (dto.SyncedLineAt, error) {
+	lines, err := s.syncedLines(ctx, songId)
+	if err != nil {
+		return dto.SyncedLineAt{}, err
+	}
+
+	index := toLRC(lines).LineAt(at)
+	result := dto.SyncedLineAt{AtMs: at.Milliseconds(), Index: index}
+
+	if index >= 0 {
+		line := syncedLine(lines[index])
+		result.Line = &line
+	}
+	if index+1 < len(lines) {
+		next := syncedLine(lines[index+1])
+		result.Next = &next
+	}
+
+	return result, nil
+}
+
+// ImportLRC заменяет синхронизированный текст песни строками из текста в формате LRC.
+// Теги файла не сохраняются: при экспорте они берутся из песни
+func (s SyncedLyricsService) ImportLRC(ctx context.Context, songId int, data string) (dto.SyncedLyrics, error) {
+	lyrics, err := lrc.Parse(data)
+	if err != nil {
+		return dto.SyncedLyrics{}, errs.Validation("некорректный LRC: %v", err)
+	}
+	if len(lyrics.Lines) == 0 {
+		return dto.SyncedLyrics{}, errs.Validation("в LRC нет строк с метками времени")
+	}
+
+	lines := make([]model.SyncedLine, len(lyrics.Lines))
+	for i, line := range lyrics.Lines {
+		lines[i] = model.SyncedLine{StartMs: line.Time.Milliseconds(), Text: line.Text}
+	}
+
+	if err := s.repo.SetSyncedLines(ctx, songId, lines); err != nil {
+		return dto.SyncedLyrics{}, err
+	}
+
+	return syncedLyrics(songId, lines), nil
+}
+
+// ExportLRC формирует синхронизированный текст песни в формате LRC с тегами названия и группы
+func (s SyncedLyricsService) ExportLRC(ctx context.Context, songId int) (string, error) {
+	lines, err := s.syncedLines(ctx, songId)
+	if err != nil {
+		return "", err
+	}
+
+	song, err := s.repo.GetSongById(ctx, songId)
+	if err != nil {
+		return "", err
+	}
+
+	lyrics := toLRC(lines)
+	lyrics.Tags = map[string]string{lrc.TagTitle: song.Song, lrc.TagArtist: song.Group}
+
+	return lrc.Format(lyrics), nil
+}
+
+func (s SyncedLyricsService) DeleteSyncedLyrics(ctx context.Context, songId int) error {
+	return s.repo.DeleteSyncedLines(ctx, songId)
+}
+
+// syncedLines возвращает строки синхронизированного текста песни или NotFound, если его нет
+func (s SyncedLyricsService) syncedLines(ctx context.Context, songId int) ([]model.SyncedLine, error) {
+	lines, err := s.repo.GetSyncedLines(ctx, songId)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, errs.NotFound("синхронизированный текст песни %d не найден", songId)
+	}
+
+	return lines, nil
+}
+
+func toLRC(lines []model.SyncedLine) lrc.Lyrics {
+	lyrics := lrc.Lyrics{Lines: make([]lrc.Line, len(lines))}
+	for i, line := range lines {
+		lyrics.Lines[i] = lrc.Line{Time: time.Duration(line.StartMs) * time.Millisecond, Text: line.Text}
+	}
+
+	return lyrics
+}
+
+func syncedLine(line model.SyncedLine) dto.SyncedLine {
+	return dto.SyncedLine{
+		Time:    lrc.FormatTime(time.Duration(line.StartMs) * time.Millisecond),
+		StartMs: line.StartMs,
+		Text:    line.Text,
+	}
+}
+
+func syncedLyrics(songId int, lines []model.SyncedLine) dto.SyncedLyrics {
+	lyrics := dto.SyncedLyrics{SongId: songId, Lines: make([]dto.SyncedLine, len(lines))}
+	for i, line := range lines {
+		lyrics.Lines[i] = syncedLine(line)
+	}
+
+	return lyrics
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type TranslationsService struct {
+	repo *repository.Repository
+}
+
+func NewTranslationsService(repo *repository.Repository) *TranslationsService {
+	return &TranslationsService{
+		repo: repo,
+	}
+}
+
+func (s TranslationsService) GetTranslations(ctx context.Context, songId int) ([]model.Translation, error) {
+	return s.repo.GetTranslations(ctx, songId)
+}
+
+func (s TranslationsService) GetTranslation(ctx context.Context, songId int, lang string) (model.Translation, error) {
+	lang, err := dto.ParseLanguage(lang)
+	if err != nil {
+		return model.Translation{}, err
+	}
+
+	return s.repo.GetTranslation(ctx, songId, lang)
+}
+
+// SaveTranslation добавляет или заменяет перевод на язык req.Lang. Текст нормализуется
+// так же, как текст песни, чтобы куплеты перевода делились одинаково
+func (s TranslationsService) SaveTranslation(ctx context.Context, req dto.SaveTranslationRequest) (model.Translation, bool, error) {
+	lang, err := dto.ParseLanguage(req.Lang)
+	if err != nil {
+		return model.Translation{}, false, err
+	}
+
+	text := dto.NormalizeText(req.Text)
+	if text == "" {
+		return model.Translation{}, false, errs.Validation("текст перевода не может быть пустым")
+	}
+
+	return s.repo.SaveTranslation(ctx, req.SongId, lang, text)
+}
+
+func (s TranslationsService) DeleteTranslation(ctx context.Context, songId int, lang string) error {
+	lang, err := dto.ParseLanguage(lang)
+	if err != nil {
+		return err
+	}
+
+	return s.repo.DeleteTranslation(ctx, songId, lang)
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+	"golang.org/x/text/language"
+)
+
+// matchTranslation выбирает перевод для запрошенного языка. Кроме точного совпадения
+// подходит близкий вариант того же языка: на запрос en-GB отдается перевод en,
+// но не перевод на другой язык или в другой письменности
+func matchTranslation(translations []model.Translation, lang string) (model.Translation, error) {
+	lang, err := dto.ParseLanguage(lang)
+	if err != nil {
+		return model.Translation{}, err
+	}
+
+	if len(translations) > 0 {
+		tags := make([]language.Tag, len(translations))
+		for i, translation := range translations {
+			tags[i] = language.Make(translation.Lang)
+		}
+
+		_, index, confidence := language.NewMatcher(tags).Match(language.Make(lang))
+		if confidence >= language.High {
+			return translations[index], nil
+		}
+	}
+
+	return model.Translation{}, errs.NotFound("перевод на язык %s не найден", lang)
+}
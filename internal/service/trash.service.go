@@ -0,0 +1,81 @@
+package service
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+const defaultTrashRetention = 30 * 24 * time.Hour
+
+// TrashRetentionFromEnv читает срок хранения песен в корзине из TRASH_RETENTION
+func TrashRetentionFromEnv() time.Duration {
+	if value, err := time.ParseDuration(os.Getenv("TRASH_RETENTION")); err == nil && value > 0 {
+		return value
+	}
+
+	return defaultTrashRetention
+}
+
+type TrashService struct {
+	repo      *repository.Repository
+	retention time.Duration
+	logger    *logging.Logger
+}
+
+func NewTrashService(repo *repository.Repository, retention time.Duration) *TrashService {
+	return &TrashService{
+		repo:      repo,
+		retention: retention,
+		logger:    logging.GetLogger(),
+	}
+}
+
+// GetTrash возвращает песни в корзине вместе со временем их окончательного удаления
+func (s TrashService) GetTrash(ctx context.Context) ([]dto.TrashedSong, error) {
+	songs, err := s.repo.GetDeletedSongs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	trash := make([]dto.TrashedSong, 0, len(songs))
+	for _, song := range songs {
+		trashed := dto.TrashedSong{Song: song}
+		if song.DeletedAt != nil {
+			trashed.PurgeAt = song.DeletedAt.Add(s.retention)
+		}
+		trash = append(trash, trashed)
+	}
+
+	return trash, nil
+}
+
+// RestoreSong возвращает песню из корзины вместе с куплетами, переводами и историей
+func (s TrashService) RestoreSong(ctx context.Context, id int) (model.Song, error) {
+	if err := s.repo.RestoreSong(ctx, id); err != nil {
+		return model.Song{}, err
+	}
+
+	s.logger.Infof("Песня %d восстановлена из корзины", id)
+
+	return s.repo.GetSongById(ctx, id)
+}
+
+// PurgeTrash окончательно удаляет песни, пролежавшие в корзине дольше срока хранения
+func (s TrashService) PurgeTrash(ctx context.Context) (int, error) {
+	purged, err := s.repo.PurgeDeletedSongs(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return 0, err
+	}
+
+	if purged > 0 {
+		s.logger.Infof("Из корзины окончательно удалено песен: %d", purged)
+	}
+
+	return purged, nil
+}
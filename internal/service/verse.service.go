@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type VersesService struct {
+	repo *repository.Repository
+}
+
+func NewVersesService(repo *repository.Repository) *VersesService {
+	return &VersesService{
+		repo: repo,
+	}
+}
+
+func (s VersesService) GetSongVerses(ctx context.Context, songId int) ([]model.Verse, error) {
+	return s.repo.GetSongVerses(ctx, songId)
+}
+
+func (s VersesService) GetVerse(ctx context.Context, songId, verseId int) (model.Verse, error) {
+	return s.repo.GetVerse(ctx, songId, verseId)
+}
+
+func (s VersesService) AddVerse(ctx context.Context, req dto.AddVerseRequest) (model.Verse, error) {
+	if req.Type == "" {
+		req.Type = model.VerseTypeVerse
+	}
+
+	var err error
+	if req.Text, err = normalizeVerseText(req.Text); err != nil {
+		return model.Verse{}, err
+	}
+
+	return s.repo.AddVerse(ctx, req)
+}
+
+func (s VersesService) UpdateVerse(ctx context.Context, req dto.UpdateVerseRequest) error {
+	if req.Type == nil && req.Text == nil {
+		return errs.Validation("не указаны изменения куплета: type или text")
+	}
+
+	if req.Text != nil {
+		text, err := normalizeVerseText(*req.Text)
+		if err != nil {
+			return err
+		}
+		req.Text = &text
+	}
+
+	return s.repo.UpdateVerse(ctx, req)
+}
+
+func (s VersesService) DeleteVerse(ctx context.Context, songId, verseId int) error {
+	return s.repo.DeleteVerse(ctx, songId, verseId)
+}
+
+func (s VersesService) ReorderVerses(ctx context.Context, req dto.ReorderVersesRequest) ([]model.Verse, error) {
+	if err := s.repo.ReorderVerses(ctx, req); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetSongVerses(ctx, req.SongId)
+}
+
+// normalizeVerseText приводит текст куплета к виду, в котором он хранится. Пустая строка
+// внутри куплета разделила бы его на два в songs.text, поэтому она считается ошибкой
+func normalizeVerseText(text string) (string, error) {
+	text = strings.Trim(dto.NormalizeLyricsText(text), "\n")
+
+	if strings.TrimSpace(text) == "" {
+		return "", errs.Validation("текст куплета не может быть пустым")
+	}
+	if strings.Contains(text, "\n\n") {
+		return "", errs.Validation("куплет не может содержать пустые строки, разделите его на несколько куплетов")
+	}
+
+	return text, nil
+}
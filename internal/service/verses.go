@@ -0,0 +1,34 @@
+package service
+
+import "github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+
+const (
+	defaultVersesPageSize = 10
+	maxVersesPageSize     = 100
+)
+
+// paginateVerses возвращает страницу page по limit куплетов
+func paginateVerses(verses []string, page, limit int) dto.SongLyrics {
+	lyrics := dto.SongLyrics{
+		Verses:      []string{},
+		Page:        page,
+		Limit:       limit,
+		TotalVerses: len(verses),
+	}
+
+	// Сравнение до умножения защищает от переполнения при огромном номере страницы
+	if page-1 > len(verses)/limit {
+		return lyrics
+	}
+
+	start := (page - 1) * limit
+	if start >= len(verses) {
+		return lyrics
+	}
+
+	end := min(start+limit, len(verses))
+	lyrics.Verses = verses[start:end]
+	lyrics.HasMore = end < len(verses)
+
+	return lyrics
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPaginateVerses(t *testing.T) {
+	verses := []string{"1", "2", "3", "4", "5"}
+
+	tests := []struct {
+		name        string
+		page, limit int
+		want        []string
+		hasMore     bool
+	}{
+		{name: "first page", page: 1, limit: 2, want: []string{"1", "2"}, hasMore: true},
+		{name: "middle page", page: 2, limit: 2, want: []string{"3", "4"}, hasMore: true},
+		{name: "last partial page", page: 3, limit: 2, want: []string{"5"}, hasMore: false},
+		{name: "exact last page", page: 1, limit: 5, want: []string{"1", "2", "3", "4", "5"}, hasMore: false},
+		{name: "past the end", page: 4, limit: 2, want: []string{}, hasMore: false},
+		{name: "huge page", page: int(^uint(0) >> 1), limit: 100, want: []string{}, hasMore: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := paginateVerses(verses, tt.page, tt.limit)
+
+			if !reflect.DeepEqual(got.Verses, tt.want) || got.HasMore != tt.hasMore {
+				t.Fatalf("verses = %q, has_more = %v; want %q, %v", got.Verses, got.HasMore, tt.want, tt.hasMore)
+			}
+			if got.TotalVerses != len(verses) || got.Page != tt.page || got.Limit != tt.limit {
+				t.Fatalf("lyrics = %+v", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+const defaultEnrichmentInterval = 5 * time.Second
+
+// EnrichmentWorker периодически разбирает очередь песен, ожидающих дополнения данными внешнего API
+type EnrichmentWorker struct {
+	enrichment service.Enrichment
+	interval   time.Duration
+	logger     *logging.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewEnrichmentWorker(services *service.Service, logger *logging.Logger) *EnrichmentWorker {
+	interval := defaultEnrichmentInterval
+	if value, err := time.ParseDuration(os.Getenv("ENRICHMENT_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+
+	return &EnrichmentWorker{
+		enrichment: services.Enrichment,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+// Start запускает обработку очереди в отдельной горутине
+func (w *EnrichmentWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx)
+
+	w.logger.Infof("Фоновое дополнение песен запущено, интервал %s", w.interval)
+}
+
+// Stop прерывает текущий проход и ждет завершения горутины, но не дольше ctx
+func (w *EnrichmentWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+
+	w.cancel()
+
+	select {
+	case <-w.done:
+		w.logger.Info("Фоновое дополнение песен остановлено")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *EnrichmentWorker) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		// Пока очередь не пуста, следующая пачка берется сразу
+		for {
+			processed, err := w.enrichment.ProcessPendingEnrichments(ctx)
+			if err != nil && ctx.Err() == nil {
+				w.logger.Errorf("Ошибка при дополнении песен: %v", err)
+			}
+			if err != nil || processed == 0 {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package worker
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/logging"
+)
+
+const defaultTrashPurgeInterval = time.Hour
+
+// TrashPurgeWorker периодически удаляет из корзины песни, срок хранения которых истек
+type TrashPurgeWorker struct {
+	trash    service.Trash
+	interval time.Duration
+	logger   *logging.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+func NewTrashPurgeWorker(services *service.Service, logger *logging.Logger) *TrashPurgeWorker {
+	interval := defaultTrashPurgeInterval
+	if value, err := time.ParseDuration(os.Getenv("TRASH_PURGE_INTERVAL")); err == nil && value > 0 {
+		interval = value
+	}
+
+	return &TrashPurgeWorker{
+		trash:    services.Trash,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// Start запускает очистку корзины в отдельной горутине
+func (w *TrashPurgeWorker) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go w.run(ctx)
+
+	w.logger.Infof("Очистка корзины запущена, интервал %s", w.interval)
+}
+
+// Stop прерывает текущую очистку и ждет завершения горутины, но не дольше ctx
+func (w *TrashPurgeWorker) Stop(ctx context.Context) error {
+	if w.cancel == nil {
+		return nil
+	}
+
+	w.cancel()
+
+	select {
+	case <-w.done:
+		w.logger.Info("Очистка корзины остановлена")
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (w *TrashPurgeWorker) run(ctx context.Context) {
+	defer close(w.done)
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.trash.PurgeTrash(ctx); err != nil && ctx.Err() == nil {
+			w.logger.Errorf("Ошибка при очистке корзины: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
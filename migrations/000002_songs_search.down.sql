@@ -0,0 +1,10 @@
+DROP INDEX IF EXISTS idx_songs_search_vector;
+
+DROP TRIGGER IF EXISTS groups_search_vector_trigger ON groups;
+DROP TRIGGER IF EXISTS songs_search_vector_trigger ON songs;
+
+DROP FUNCTION IF EXISTS groups_search_vector_update();
+DROP FUNCTION IF EXISTS songs_search_vector_update();
+DROP FUNCTION IF EXISTS songs_search_vector(TEXT, TEXT, TEXT, TEXT);
+
+ALTER TABLE songs DROP COLUMN IF EXISTS search_vector;
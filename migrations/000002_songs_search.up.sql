@@ -0,0 +1,46 @@
+ALTER TABLE songs ADD COLUMN search_vector TSVECTOR;
+
+-- Название песни и группы важнее жанра, жанр важнее текста
+CREATE OR REPLACE FUNCTION songs_search_vector(p_song TEXT, p_group TEXT, p_genre TEXT, p_text TEXT)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', coalesce(p_song, '')), 'A') ||
+           setweight(to_tsvector('simple', coalesce(p_group, '')), 'B') ||
+           setweight(to_tsvector('simple', coalesce(p_genre, '')), 'C') ||
+           setweight(to_tsvector('simple', coalesce(p_text, '')), 'D')
+$$ LANGUAGE SQL IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION songs_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := songs_search_vector(
+        NEW.song,
+        (SELECT name FROM groups WHERE id = NEW.group_id),
+        NEW.genre,
+        NEW.text
+    );
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER songs_search_vector_trigger
+    BEFORE INSERT OR UPDATE OF song, genre, text, group_id ON songs
+    FOR EACH ROW EXECUTE FUNCTION songs_search_vector_update();
+
+-- При переименовании группы пересчитываем вектор всех ее песен
+CREATE OR REPLACE FUNCTION groups_search_vector_update() RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE songs
+    SET search_vector = songs_search_vector(song, NEW.name, genre, text)
+    WHERE group_id = NEW.id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER groups_search_vector_trigger
+    AFTER UPDATE OF name ON groups
+    FOR EACH ROW WHEN (OLD.name IS DISTINCT FROM NEW.name)
+    EXECUTE FUNCTION groups_search_vector_update();
+
+UPDATE songs s
+SET search_vector = songs_search_vector(s.song, (SELECT name FROM groups g WHERE g.id = s.group_id), s.genre, s.text);
+
+CREATE INDEX idx_songs_search_vector ON songs USING GIN (search_vector);
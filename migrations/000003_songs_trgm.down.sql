@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_groups_name_trgm;
+DROP INDEX IF EXISTS idx_songs_song_trgm;
+
+-- Расширение pg_trgm не удаляем: оно может использоваться вне этой схемы
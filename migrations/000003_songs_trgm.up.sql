@@ -0,0 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+-- Индексы для нечеткого поиска и автодополнения (similarity, word_similarity, ILIKE 'prefix%')
+CREATE INDEX idx_songs_song_trgm ON songs USING GIN (song gin_trgm_ops);
+CREATE INDEX idx_groups_name_trgm ON groups USING GIN (name gin_trgm_ops);
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS idx_songs_pending_enrichment;
+
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS enrichment_error,
+    DROP COLUMN IF EXISTS next_enrichment_at,
+    DROP COLUMN IF EXISTS enrichment_attempts,
+    DROP COLUMN IF EXISTS enrichment_status;
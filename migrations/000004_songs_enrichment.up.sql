@@ -0,0 +1,13 @@
+-- Статус дополнения песни данными внешнего API: pending — ожидает (в том числе повтора),
+-- enriched — дополнена, failed — попытки исчерпаны или внешний API песню не знает.
+-- Песни, добавленные до асинхронного дополнения, уже дополнены
+ALTER TABLE songs
+    ADD COLUMN enrichment_status VARCHAR(16) NOT NULL DEFAULT 'enriched'
+        CHECK (enrichment_status IN ('pending', 'enriched', 'failed')),
+    ADD COLUMN enrichment_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN next_enrichment_at TIMESTAMPTZ,
+    ADD COLUMN enrichment_error TEXT;
+
+-- Очередь фонового обработчика: только песни, ожидающие дополнения
+CREATE INDEX idx_songs_pending_enrichment ON songs (next_enrichment_at)
+    WHERE enrichment_status = 'pending';
@@ -0,0 +1,9 @@
+ALTER TABLE songs
+    DROP COLUMN IF EXISTS enriched_fields,
+    DROP COLUMN IF EXISTS enrichment_policy;
+
+UPDATE songs SET enrichment_status = 'enriched' WHERE enrichment_status = 'skipped';
+
+ALTER TABLE songs DROP CONSTRAINT songs_enrichment_status_check;
+ALTER TABLE songs ADD CONSTRAINT songs_enrichment_status_check
+    CHECK (enrichment_status IN ('pending', 'enriched', 'failed'));
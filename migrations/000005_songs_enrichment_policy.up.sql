@@ -0,0 +1,12 @@
+-- skipped — дополнение не требуется: клиент запретил его (enrich=never) или заполнил все поля сам
+ALTER TABLE songs DROP CONSTRAINT songs_enrichment_status_check;
+ALTER TABLE songs ADD CONSTRAINT songs_enrichment_status_check
+    CHECK (enrichment_status IN ('pending', 'enriched', 'failed', 'skipped'));
+
+-- Политика слияния с данными внешнего API: never — не дополнять, missing — заполнять
+-- только пустые поля, always — данные внешнего API важнее переданных клиентом.
+-- enriched_fields — поля, значения которых взяты из внешнего API
+ALTER TABLE songs
+    ADD COLUMN enrichment_policy VARCHAR(16) NOT NULL DEFAULT 'missing'
+        CHECK (enrichment_policy IN ('never', 'missing', 'always')),
+    ADD COLUMN enriched_fields TEXT[] NOT NULL DEFAULT '{}';
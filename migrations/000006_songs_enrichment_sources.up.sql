@@ -0,0 +1,2 @@
+-- Имя поставщика метаданных для каждого поля из enriched_fields, например {"text": "catalog"}
+ALTER TABLE songs ADD COLUMN enrichment_sources JSONB NOT NULL DEFAULT '{}';
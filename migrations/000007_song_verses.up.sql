@@ -0,0 +1,27 @@
+-- Куплеты песни в порядке исполнения. songs.text остается производным значением:
+-- куплеты, соединенные пустой строкой, и пересчитывается при каждом изменении куплетов
+CREATE TABLE song_verses (
+    id SERIAL PRIMARY KEY,
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position > 0),
+    type VARCHAR(16) NOT NULL DEFAULT 'verse'
+        CHECK (type IN ('verse', 'chorus', 'bridge', 'intro', 'outro')),
+    text TEXT NOT NULL CHECK (text <> ''),
+    -- Отложенная проверка позволяет сдвигать куплеты одним UPDATE
+    CONSTRAINT song_verses_song_id_position_key UNIQUE (song_id, position) DEFERRABLE INITIALLY DEFERRED
+);
+
+-- Делим существующие тексты так же, как это делает приложение: переводы строк приводятся к \n,
+-- пробелы в конце строк отбрасываются, куплеты разделяются одной или несколькими пустыми строками
+INSERT INTO song_verses (song_id, position, type, text)
+SELECT s.id, row_number() OVER (PARTITION BY s.id ORDER BY p.ord), 'verse', btrim(p.part, E'\n')
+FROM songs s,
+     regexp_split_to_table(
+         regexp_replace(replace(replace(s.text, E'\r\n', E'\n'), E'\r', E'\n'), E'[ \t]+$', '', 'gn'),
+         E'\n{2,}'
+     ) WITH ORDINALITY AS p(part, ord)
+WHERE s.text IS NOT NULL AND btrim(p.part, E'\n') <> '';
+
+UPDATE songs s
+SET text = COALESCE((SELECT string_agg(v.text, E'\n\n' ORDER BY v.position) FROM song_verses v WHERE v.song_id = s.id), '')
+WHERE s.text IS NOT NULL;
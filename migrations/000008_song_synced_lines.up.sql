@@ -0,0 +1,9 @@
+-- Синхронизированный текст песни (LRC): строки с временем начала в миллисекундах.
+-- Хранится отдельно от songs.text и куплетов, строка с пустым текстом означает паузу
+CREATE TABLE song_synced_lines (
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    position INT NOT NULL CHECK (position > 0),
+    start_ms INT NOT NULL CHECK (start_ms >= 0),
+    text TEXT NOT NULL,
+    PRIMARY KEY (song_id, position)
+);
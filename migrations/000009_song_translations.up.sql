@@ -0,0 +1,9 @@
+-- Переводы текста песни. Язык хранится тегом BCP-47 в каноническом виде (en, en-GB, pt-BR),
+-- куплеты перевода разделяются пустой строкой, как в songs.text
+CREATE TABLE song_translations (
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    lang VARCHAR(35) NOT NULL,
+    text TEXT NOT NULL CHECK (text <> ''),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (song_id, lang)
+);
@@ -0,0 +1,33 @@
+-- Неизменяемая история песни: каждая запись песни сохраняет снимок ее полей вместе с автором
+-- и причиной изменения. Номера ревизий песни идут подряд, начиная с 1
+CREATE TABLE song_revisions (
+    song_id INT NOT NULL REFERENCES songs(id) ON DELETE CASCADE,
+    revision INT NOT NULL CHECK (revision > 0),
+    -- Снимок хранится в TEXT, чтобы не зависеть от ограничений длины в songs и groups
+    song TEXT,
+    group_name TEXT,
+    genre TEXT,
+    release_date DATE,
+    text TEXT,
+    link TEXT,
+    actor TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (song_id, revision)
+);
+
+CREATE FUNCTION song_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ревизии песен нельзя изменять';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER song_revisions_immutable
+    BEFORE UPDATE ON song_revisions
+    FOR EACH ROW EXECUTE FUNCTION song_revisions_immutable();
+
+-- Текущее состояние существующих песен становится их первой ревизией
+INSERT INTO song_revisions (song_id, revision, song, group_name, genre, release_date, text, link, actor, reason)
+SELECT s.id, 1, s.song, g.name, s.genre, s.releaseDate, s.text, s.link, 'system', 'состояние до ведения истории'
+FROM songs s
+LEFT JOIN groups g ON s.group_id = g.id;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_songs_deleted_at;
+
+ALTER TABLE songs DROP COLUMN IF EXISTS deleted_at;
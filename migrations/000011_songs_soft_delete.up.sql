@@ -0,0 +1,7 @@
+-- Удаленная песня попадает в корзину: строка остается, пока ее не восстановят
+-- или не удалит окончательно фоновая очистка по истечении срока хранения
+ALTER TABLE songs ADD COLUMN deleted_at TIMESTAMPTZ;
+
+-- Корзина и очистка выбирают только удаленные песни
+CREATE INDEX idx_songs_deleted_at ON songs (deleted_at)
+    WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Версия песни растет при каждом изменении ее представления (GET /song/{id}) и служит ETag:
+-- клиент передает ее в If-Match, чтобы не затереть чужие изменения
+ALTER TABLE songs ADD COLUMN version INT NOT NULL DEFAULT 1 CHECK (version > 0);
@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS song_revisions_no_delete ON song_revisions;
+
+CREATE OR REPLACE FUNCTION song_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'ревизии песен нельзя изменять';
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,15 @@
+-- Ревизии нельзя и удалять. Исключение — каскадное удаление вместе с песней,
+-- когда корзина окончательно очищается: к этому моменту строки песни уже нет
+CREATE OR REPLACE FUNCTION song_revisions_immutable() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'DELETE' AND NOT EXISTS (SELECT 1 FROM songs WHERE id = OLD.song_id) THEN
+        RETURN OLD;
+    END IF;
+
+    RAISE EXCEPTION 'ревизии песен нельзя изменять';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER song_revisions_no_delete
+    BEFORE DELETE ON song_revisions
+    FOR EACH ROW EXECUTE FUNCTION song_revisions_immutable();
@@ -0,0 +1,20 @@
+-- Название группы уникально без учета регистра. Проверка в коде дает понятное сообщение,
+-- а одновременные вставки и переименования разводит только индекс
+DO $$
+DECLARE
+    duplicates TEXT;
+BEGIN
+    SELECT string_agg(ids, '; ') INTO duplicates
+    FROM (
+        SELECT lower(name) || ': ' || string_agg(id::text, ', ' ORDER BY id) AS ids
+        FROM groups
+        GROUP BY lower(name)
+        HAVING COUNT(*) > 1
+    ) d;
+
+    IF duplicates IS NOT NULL THEN
+        RAISE EXCEPTION 'группы с совпадающими названиями: %. Объедините их через POST /api/v1/groups/merge и повторите миграцию', duplicates;
+    END IF;
+END $$;
+
+CREATE UNIQUE INDEX idx_groups_name_lower ON groups (lower(name));
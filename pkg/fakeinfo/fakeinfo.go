@@ -0,0 +1,151 @@
+// Package fakeinfo реализует поставщика информации о песнях в памяти для тестов
+// и встраивания сервиса без внешнего API. Пакет не входит в сборку сервиса
+package fakeinfo
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+// Provider имя поставщика API в enrichmentSources
+const Provider = "fake"
+
+// API реализация service.ExternalAPI в памяти.
+// Отдает заданные через SetSongDetails данные; как и external_api.Chain, на неизвестную песню отвечает
+// errs.Validation, а поставщиком заполненных полей указывает Provider, если Sources не заданы явно.
+// Ошибку для отдельной песни или для всех запросов можно задать через SetError и SetDefaultError,
+// а действие во время запроса — через SetOnCall
+type API struct {
+	mu         sync.Mutex
+	details    map[string]dto.SongDetails
+	errors     map[string]error
+	defaultErr error
+	onCall     func(group, song string)
+	calls      []Call
+}
+
+// Call запрос к API
+type Call struct {
+	Group string
+	Song  string
+}
+
+func NewAPI() *API {
+	return &API{
+		details: make(map[string]dto.SongDetails),
+		errors:  make(map[string]error),
+	}
+}
+
+// SetSongDetails задает ответ для песни; группа и название сравниваются без учета регистра
+func (f *API) SetSongDetails(group, song string, details dto.SongDetails) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.details[songKey(group, song)] = details
+}
+
+// SetError задает ошибку для песни; nil снимает ее
+func (f *API) SetError(group, song string, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if err == nil {
+		delete(f.errors, songKey(group, song))
+		return
+	}
+	f.errors[songKey(group, song)] = err
+}
+
+// SetDefaultError задает ошибку для всех запросов, например чтобы имитировать
+// недоступность внешнего API; nil снимает ее
+func (f *API) SetDefaultError(err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.defaultErr = err
+}
+
+// SetOnCall задает функцию, которая вызывается при каждом запросе до ответа, например
+// чтобы изменить песню, пока обработчик ждет внешний API; nil снимает ее
+func (f *API) SetOnCall(onCall func(group, song string)) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.onCall = onCall
+}
+
+// Calls возвращает запросы к API в порядке поступления
+func (f *API) Calls() []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return append([]Call{}, f.calls...)
+}
+
+func (f *API) GetSongDetails(ctx context.Context, group, song string) (*dto.SongDetails, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	f.mu.Lock()
+	onCall := f.onCall
+	f.mu.Unlock()
+
+	if onCall != nil {
+		onCall(group, song)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls = append(f.calls, Call{Group: group, Song: song})
+
+	key := songKey(group, song)
+	if err, ok := f.errors[key]; ok {
+		return nil, err
+	}
+	if f.defaultErr != nil {
+		return nil, f.defaultErr
+	}
+
+	details, ok := f.details[key]
+	if !ok {
+		return nil, errs.Validation("песни %q группы %q нет в fakeinfo", song, group)
+	}
+
+	// Копия защищает заданный ответ от изменений вызывающим кодом
+	result := details
+	if result.Sources != nil {
+		result.Sources = make(map[string]string, len(details.Sources))
+		for field, source := range details.Sources {
+			result.Sources[field] = source
+		}
+	} else {
+		result.Sources = map[string]string{}
+		for field, value := range map[string]string{
+			model.FieldReleaseDate: result.ReleaseDate,
+			model.FieldText:        result.Text,
+			model.FieldLink:        result.Link,
+		} {
+			if value != "" {
+				result.Sources[field] = Provider
+			}
+		}
+	}
+
+	return &result, nil
+}
+
+// songKey ключ поиска песни без учета регистра и лишних пробелов, как в external_api
+func songKey(group, song string) string {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.Join(strings.Fields(value), " "))
+	}
+	return normalize(group) + "\x00" + normalize(song)
+}
@@ -0,0 +1,114 @@
+// Package linediff строит построчную разницу двух текстов по наибольшей общей
+// подпоследовательности строк
+package linediff
+
+import "strings"
+
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Delete Op = "delete"
+	Insert Op = "insert"
+)
+
+// Edit строка результата: общая для обоих текстов, удаленная из первого или добавленная во второй
+type Edit struct {
+	Op   Op     `json:"op"`
+	Text string `json:"text"`
+}
+
+// maxTableSize ограничивает таблицу LCS. Если изменившаяся часть текстов больше,
+// она целиком считается удаленной и добавленной заново
+const maxTableSize = 4_000_000
+
+// Lines сравнивает тексты построчно. Удаленные строки идут перед добавленными на их место
+func Lines(a, b string) []Edit {
+	return Diff(splitLines(a), splitLines(b))
+}
+
+// Diff сравнивает последовательности строк
+func Diff(a, b []string) []Edit {
+	var edits []Edit
+
+	// Общие начало и конец не участвуют в LCS
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	for _, line := range a[:prefix] {
+		edits = append(edits, Edit{Op: Equal, Text: line})
+	}
+	edits = append(edits, diffMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		edits = append(edits, Edit{Op: Equal, Text: line})
+	}
+
+	return edits
+}
+
+func diffMiddle(a, b []string) []Edit {
+	if (len(a)+1)*(len(b)+1) > maxTableSize {
+		return replaceAll(a, b)
+	}
+
+	// lcs[i][j] длина общей подпоследовательности a[i:] и b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			edits = append(edits, Edit{Op: Equal, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, Edit{Op: Delete, Text: a[i]})
+			i++
+		default:
+			edits = append(edits, Edit{Op: Insert, Text: b[j]})
+			j++
+		}
+	}
+
+	return append(edits, replaceAll(a[i:], b[j:])...)
+}
+
+func replaceAll(a, b []string) []Edit {
+	edits := make([]Edit, 0, len(a)+len(b))
+	for _, line := range a {
+		edits = append(edits, Edit{Op: Delete, Text: line})
+	}
+	for _, line := range b {
+		edits = append(edits, Edit{Op: Insert, Text: line})
+	}
+
+	return edits
+}
+
+// splitLines делит текст на строки; у пустого текста строк нет
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,60 @@
+package linediff
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Edit
+	}{
+		{name: "equal", a: "one\ntwo", b: "one\ntwo", want: []Edit{{Equal, "one"}, {Equal, "two"}}},
+		{name: "both empty", a: "", b: "", want: nil},
+		{name: "added text", a: "", b: "one\ntwo", want: []Edit{{Insert, "one"}, {Insert, "two"}}},
+		{name: "removed text", a: "one", b: "", want: []Edit{{Delete, "one"}}},
+		{
+			name: "changed line",
+			a:    "It's bugging me\n\nGrating me\n\nAnd twisting me around",
+			b:    "It's bugging me\n\nHATING me\n\nAnd twisting me around",
+			want: []Edit{{Equal, "It's bugging me"}, {Equal, ""}, {Delete, "Grating me"}, {Insert, "HATING me"}, {Equal, ""}, {Equal, "And twisting me around"}},
+		},
+		{
+			name: "insert and delete in the middle",
+			a:    "a\nb\nc\nd\ne",
+			b:    "a\nc\nd\nx\ne",
+			want: []Edit{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}, {Equal, "d"}, {Insert, "x"}, {Equal, "e"}},
+		},
+		{
+			name: "moved line",
+			a:    "a\nb\nc",
+			b:    "c\na\nb",
+			want: []Edit{{Insert, "c"}, {Equal, "a"}, {Equal, "b"}, {Delete, "c"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Lines(tt.a, tt.b); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Lines = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffLargeInput(t *testing.T) {
+	a := make([]string, 3000)
+	b := make([]string, 3000)
+	for i := range a {
+		a[i] = "a"
+		b[i] = "b"
+	}
+	a[0], b[0] = "same", "same"
+
+	edits := Diff(a, b)
+	if len(edits) != 1+2*2999 || edits[0] != (Edit{Equal, "same"}) {
+		t.Fatalf("got %d edits starting with %v", len(edits), edits[0])
+	}
+}
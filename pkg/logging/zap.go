@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -80,6 +81,14 @@ func Init() {
 	logger.Info("Zap логгер инициализирован")
 }
 
+// InitNop инициализирует логгер, который ничего не пишет. Используется в тестах,
+// чтобы не создавать каталог logs и не засорять вывод
+func InitNop() {
+	logger = &Logger{
+		zap: zap.NewNop().Sugar(),
+	}
+}
+
 // Обертки для совместимости с предыдущим интерфейсом
 
 func (l *Logger) Debug(args ...interface{}) {
@@ -128,7 +137,7 @@ func getCaller() string {
 	if !ok {
 		return "unknown:0"
 	}
-	return filepath.Base(file) + ":" + string(line)
+	return filepath.Base(file) + ":" + strconv.Itoa(line)
 }
 
 // Явная инициализация логгера (вызывается из main)
@@ -0,0 +1,233 @@
+// Package lrc разбирает и формирует синхронизированные тексты песен в формате LRC:
+// строки вида [mm:ss.xx]текст и теги вида [ar:Исполнитель]. Строка может иметь
+// несколько меток времени, если она повторяется в песне (например, припев)
+package lrc
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Стандартные теги LRC
+const (
+	TagTitle  = "ti"
+	TagArtist = "ar"
+	TagAlbum  = "al"
+	TagLength = "length"
+	// TagOffset сдвиг всех меток в миллисекундах. Положительный сдвиг показывает строки раньше
+	TagOffset = "offset"
+)
+
+// tagOrder порядок, в котором Format выводит известные теги; остальные идут за ними по алфавиту
+var tagOrder = []string{TagTitle, TagArtist, TagAlbum, TagLength}
+
+var ErrInvalidTime = errors.New("некорректная метка времени")
+
+// Line строка текста, которая начинает звучать в момент Time. Пустой Text означает
+// паузу, на которой плеер убирает текст с экрана
+type Line struct {
+	Time time.Duration
+	Text string
+}
+
+type Lyrics struct {
+	// Tags теги с ключами в нижнем регистре. Сдвиг offset при разборе применяется к строкам
+	// и в Tags не попадает
+	Tags map[string]string
+	// Lines строки по возрастанию времени
+	Lines []Line
+}
+
+// Parse разбирает текст в формате LRC. Пустые строки пропускаются, строка с текстом
+// без метки времени считается ошибкой
+func Parse(data string) (Lyrics, error) {
+	lyrics := Lyrics{Tags: map[string]string{}}
+
+	data = strings.TrimPrefix(data, "\ufeff")
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.ReplaceAll(data, "\r", "\n")
+
+	for i, raw := range strings.Split(data, "\n") {
+		lineNumber := i + 1
+
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			continue
+		}
+
+		var times []time.Duration
+		for strings.HasPrefix(line, "[") {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return Lyrics{}, fmt.Errorf("строка %d: не закрыта скобка", lineNumber)
+			}
+
+			label := line[1:end]
+			if t, err := ParseTime(label); err == nil {
+				times = append(times, t)
+				line = line[end+1:]
+				continue
+			}
+
+			// Тег занимает строку целиком и не смешивается с метками времени
+			key, value, ok := strings.Cut(label, ":")
+			if !ok || len(times) > 0 || strings.TrimSpace(line[end+1:]) != "" || !isTagKey(key) {
+				return Lyrics{}, fmt.Errorf("строка %d: %w %q", lineNumber, ErrInvalidTime, label)
+			}
+			lyrics.Tags[strings.ToLower(key)] = strings.TrimSpace(value)
+			line = ""
+		}
+
+		if len(times) == 0 {
+			if line != "" {
+				return Lyrics{}, fmt.Errorf("строка %d: нет метки времени", lineNumber)
+			}
+			continue
+		}
+
+		text := strings.TrimSpace(line)
+		for _, t := range times {
+			lyrics.Lines = append(lyrics.Lines, Line{Time: t, Text: text})
+		}
+	}
+
+	if value, ok := lyrics.Tags[TagOffset]; ok {
+		offset, err := strconv.Atoi(strings.TrimPrefix(value, "+"))
+		if err != nil {
+			return Lyrics{}, fmt.Errorf("некорректный сдвиг offset %q", value)
+		}
+		delete(lyrics.Tags, TagOffset)
+
+		for i := range lyrics.Lines {
+			lyrics.Lines[i].Time = max(lyrics.Lines[i].Time-time.Duration(offset)*time.Millisecond, 0)
+		}
+	}
+
+	// Стабильная сортировка сохраняет порядок строк с одинаковым временем
+	sort.SliceStable(lyrics.Lines, func(i, j int) bool {
+		return lyrics.Lines[i].Time < lyrics.Lines[j].Time
+	})
+
+	return lyrics, nil
+}
+
+// Format формирует текст в формате LRC: сначала теги, затем строки по одной метке на строку
+func Format(lyrics Lyrics) string {
+	var b strings.Builder
+
+	keys := make([]string, 0, len(lyrics.Tags))
+	for key := range lyrics.Tags {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return tagRank(keys[i]) < tagRank(keys[j]) || tagRank(keys[i]) == tagRank(keys[j]) && keys[i] < keys[j]
+	})
+
+	for _, key := range keys {
+		fmt.Fprintf(&b, "[%s:%s]\n", key, lyrics.Tags[key])
+	}
+
+	for _, line := range lyrics.Lines {
+		fmt.Fprintf(&b, "[%s]%s\n", FormatTime(line.Time), line.Text)
+	}
+
+	return b.String()
+}
+
+// ParseTime разбирает метку времени mm:ss, mm:ss.x, mm:ss.xx или mm:ss.xxx.
+// Вместо точки допускается двоеточие: mm:ss:xx. Минут может быть больше 59
+func ParseTime(value string) (time.Duration, error) {
+	minutes, rest, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("%w %q", ErrInvalidTime, value)
+	}
+
+	seconds, fraction, hasFraction := strings.Cut(rest, ".")
+	if !hasFraction {
+		seconds, fraction, hasFraction = strings.Cut(rest, ":")
+	}
+
+	// Четырех цифр минут хватает с запасом и исключает переполнение time.Duration
+	m, err := parseDigits(minutes)
+	if err != nil || len(minutes) > 4 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidTime, value)
+	}
+
+	s, err := parseDigits(seconds)
+	if err != nil || len(seconds) != 2 || s >= 60 {
+		return 0, fmt.Errorf("%w %q", ErrInvalidTime, value)
+	}
+
+	t := time.Duration(m)*time.Minute + time.Duration(s)*time.Second
+
+	if hasFraction {
+		f, err := parseDigits(fraction)
+		if err != nil || len(fraction) > 3 {
+			return 0, fmt.Errorf("%w %q", ErrInvalidTime, value)
+		}
+		// .5 — пятьсот миллисекунд, .05 — пятьдесят
+		for i := len(fraction); i < 3; i++ {
+			f *= 10
+		}
+		t += time.Duration(f) * time.Millisecond
+	}
+
+	return t, nil
+}
+
+// FormatTime формирует метку времени mm:ss.xx. Миллисекунды отбрасываются до сотых,
+// как принято в LRC
+func FormatTime(t time.Duration) string {
+	t = max(t, 0)
+
+	minutes := t / time.Minute
+	seconds := (t % time.Minute) / time.Second
+	hundredths := (t % time.Second) / (10 * time.Millisecond)
+
+	return fmt.Sprintf("%02d:%02d.%02d", minutes, seconds, hundredths)
+}
+
+// LineAt возвращает индекс строки, которая звучит в момент at: последней строки,
+// начавшейся не позже at. До первой строки возвращает -1
+func (l Lyrics) LineAt(at time.Duration) int {
+	return sort.Search(len(l.Lines), func(i int) bool {
+		return l.Lines[i].Time > at
+	}) - 1
+}
+
+func parseDigits(value string) (int, error) {
+	if value == "" || strings.TrimLeft(value, "0123456789") != "" {
+		return 0, strconv.ErrSyntax
+	}
+
+	return strconv.Atoi(value)
+}
+
+// isTagKey проверяет, что ключ тега состоит из латинских букв, как ti, ar или offset
+func isTagKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, r := range key {
+		if (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') {
+			return false
+		}
+	}
+
+	return true
+}
+
+func tagRank(key string) int {
+	for i, known := range tagOrder {
+		if key == known {
+			return i
+		}
+	}
+
+	return len(tagOrder)
+}
@@ -0,0 +1,156 @@
+package lrc
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func ms(value int) time.Duration {
+	return time.Duration(value) * time.Millisecond
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		wantTags map[string]string
+		want     []Line
+	}{
+		{
+			name:     "tags and lines",
+			data:     "[ti:Hysteria]\n[ar:Muse]\n\n[00:12.34]It's bugging me\n[00:15.50]Grating me\n",
+			wantTags: map[string]string{"ti": "Hysteria", "ar": "Muse"},
+			want:     []Line{{ms(12340), "It's bugging me"}, {ms(15500), "Grating me"}},
+		},
+		{
+			name:     "repeated line is expanded and sorted",
+			data:     "[00:30.00][00:10.00]Chorus\n[00:20.00]Verse",
+			wantTags: map[string]string{},
+			want:     []Line{{ms(10000), "Chorus"}, {ms(20000), "Verse"}, {ms(30000), "Chorus"}},
+		},
+		{
+			name:     "time formats",
+			data:     "[01:02]a\n[01:02.5]b\n[01:02.05]c\n[01:02.005]d\n[01:02:50]e\n[120:00.00]f",
+			wantTags: map[string]string{},
+			want: []Line{
+				{ms(62000), "a"}, {ms(62005), "d"}, {ms(62050), "c"}, {ms(62500), "b"}, {ms(62500), "e"},
+				{120 * time.Minute, "f"},
+			},
+		},
+		{
+			name:     "offset shifts lines earlier",
+			data:     "[offset:+500]\n[00:00.20]first\n[00:01.00]second",
+			wantTags: map[string]string{},
+			want:     []Line{{0, "first"}, {ms(500), "second"}},
+		},
+		{
+			name:     "negative offset shifts lines later",
+			data:     "[offset:-250]\n[00:01.00]line",
+			wantTags: map[string]string{},
+			want:     []Line{{ms(1250), "line"}},
+		},
+		{
+			name:     "CRLF, BOM and pauses",
+			data:     "\ufeff[AR: Queen ]\r\n[00:01.00] Is this the real life? \r\n[00:04.00]\r\n",
+			wantTags: map[string]string{"ar": "Queen"},
+			want:     []Line{{ms(1000), "Is this the real life?"}, {ms(4000), ""}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lyrics, err := Parse(tt.data)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+
+			if !reflect.DeepEqual(lyrics.Tags, tt.wantTags) {
+				t.Fatalf("tags = %v, want %v", lyrics.Tags, tt.wantTags)
+			}
+			if !reflect.DeepEqual(lyrics.Lines, tt.want) {
+				t.Fatalf("lines = %v, want %v", lyrics.Lines, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "line without time", data: "[00:01.00]ok\nplain text"},
+		{name: "unclosed bracket", data: "[00:01.00"},
+		{name: "seconds out of range", data: "[00:61.00]line"},
+		{name: "one-digit seconds", data: "[00:1.00]line"},
+		{name: "long fraction", data: "[00:01.0000]line"},
+		{name: "too many minutes", data: "[99999:00.00]line"},
+		{name: "tag after time", data: "[00:01.00][ar:Muse]"},
+		{name: "tag with text", data: "[ar:Muse]text"},
+		{name: "invalid offset", data: "[offset:soon]\n[00:01.00]line"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if lyrics, err := Parse(tt.data); err == nil {
+				t.Fatalf("Parse succeeded: %+v", lyrics)
+			}
+		})
+	}
+}
+
+func TestParseTime(t *testing.T) {
+	if _, err := ParseTime("1:2"); !errors.Is(err, ErrInvalidTime) {
+		t.Fatalf("err = %v, want ErrInvalidTime", err)
+	}
+
+	got, err := ParseTime("03:25.75")
+	if err != nil || got != ms(205750) {
+		t.Fatalf("ParseTime = %v, %v", got, err)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	lyrics := Lyrics{
+		Tags:  map[string]string{"by": "editor", "ar": "Muse", "ti": "Hysteria"},
+		Lines: []Line{{ms(12345), "It's bugging me"}, {ms(61000), ""}, {125 * time.Minute, "end"}},
+	}
+
+	want := "[ti:Hysteria]\n[ar:Muse]\n[by:editor]\n[00:12.34]It's bugging me\n[01:01.00]\n[125:00.00]end\n"
+	got := Format(lyrics)
+	if got != want {
+		t.Fatalf("Format = %q, want %q", got, want)
+	}
+
+	parsed, err := Parse(got)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(parsed.Lines) != 3 || parsed.Lines[0].Time != ms(12340) || parsed.Tags["by"] != "editor" {
+		t.Fatalf("round trip = %+v", parsed)
+	}
+}
+
+func TestLineAt(t *testing.T) {
+	lyrics := Lyrics{Lines: []Line{{ms(1000), "a"}, {ms(2000), "b"}, {ms(2000), "c"}, {ms(5000), "d"}}}
+
+	tests := []struct {
+		at   time.Duration
+		want int
+	}{
+		{at: 0, want: -1},
+		{at: ms(999), want: -1},
+		{at: ms(1000), want: 0},
+		{at: ms(1999), want: 0},
+		{at: ms(2000), want: 2},
+		{at: time.Hour, want: 3},
+	}
+
+	for _, tt := range tests {
+		if got := lyrics.LineAt(tt.at); got != tt.want {
+			t.Errorf("LineAt(%v) = %d, want %d", tt.at, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type EnrichmentMemory struct {
+	store *Store
+}
+
+func NewEnrichmentMemory(store *Store) *EnrichmentMemory {
+	return &EnrichmentMemory{store: store}
+}
+
+// ClaimSongsForEnrichment забирает до limit песен, ожидающих дополнения, и откладывает
+// их следующую попытку на lease
+func (m EnrichmentMemory) ClaimSongsForEnrichment(ctx context.Context, limit int, lease time.Duration) ([]model.EnrichmentTask, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	now := m.store.now()
+
+	var due []*songRecord
+	for _, record := range m.store.sortedSongs() {
+		if record.enrichmentStatus == model.EnrichmentPending && !record.nextEnrichmentAt.After(now) {
+			due = append(due, record)
+		}
+	}
+	sort.SliceStable(due, func(i, j int) bool {
+		return due[i].nextEnrichmentAt.Before(due[j].nextEnrichmentAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	tasks := []model.EnrichmentTask{}
+	for _, record := range due {
+		record.enrichmentAttempts++
+		record.nextEnrichmentAt = now.Add(lease)
+
+		task := model.EnrichmentTask{
+			SongID:   record.id,
+			Song:     record.song,
+			Group:    m.store.groups[record.groupId].Name,
+			Attempts: record.enrichmentAttempts,
+			Policy:   record.enrichmentPolicy,
+			Text:     record.text,
+			Link:     record.link,
+			Version:  record.version,
+		}
+		if !record.releaseDate.IsZero() {
+			task.ReleaseDate = record.releaseDate.Format(dto.ISODateLayout)
+		}
+
+		tasks = append(tasks, task)
+	}
+
+	return tasks, nil
+}
+
+// CompleteEnrichment сохраняет данные внешнего API в полях fields, отмечает их в enrichedFields
+// и запоминает поставщиков из details.Sources. Если песню изменили после выбора,
+// она возвращается в очередь, как в postgres-реализации
+func (m EnrichmentMemory) CompleteEnrichment(ctx context.Context, id, version int, details dto.SongDetails, fields []string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	// Песня могла попасть в корзину во время запроса к внешнему API
+	record, ok := m.store.anySong(id)
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена", id)
+	}
+
+	if record.version != version {
+		if record.enrichmentStatus == model.EnrichmentPending {
+			record.nextEnrichmentAt = m.store.now()
+		}
+		return errs.PreconditionFailed("песня %d изменена во время дополнения: версия %d, ожидалась %d", id, record.version, version)
+	}
+
+	for _, field := range fields {
+		switch field {
+		case model.FieldReleaseDate:
+			releaseDate, err := parseDate(details.ReleaseDate)
+			if err != nil {
+				return err
+			}
+			record.releaseDate = releaseDate
+		case model.FieldText:
+			m.store.setText(record, details.Text)
+		case model.FieldLink:
+			record.link = details.Link
+		}
+
+		record.enrichedFields = append(removeString(record.enrichedFields, field), field)
+		if source, ok := details.Sources[field]; ok {
+			record.enrichmentSources[field] = source
+		}
+	}
+	sort.Strings(record.enrichedFields)
+
+	if len(fields) > 0 {
+		m.store.recordRevision(ctx, record)
+	}
+
+	record.enrichmentStatus = model.EnrichmentEnriched
+	record.nextEnrichmentAt = time.Time{}
+	record.enrichmentError = ""
+	record.version++
+
+	return nil
+}
+
+// FailEnrichment записывает ошибку дополнения. Если retryAt задан, песня остается
+// в очереди до этого момента, иначе получает статус failed. Версия песни растет только
+// при смене статуса
+func (m EnrichmentMemory) FailEnrichment(ctx context.Context, id int, reason string, retryAt *time.Time) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.anySong(id)
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена", id)
+	}
+
+	status := record.enrichmentStatus
+	record.enrichmentStatus = model.EnrichmentFailed
+	record.nextEnrichmentAt = time.Time{}
+	if retryAt != nil {
+		record.enrichmentStatus = model.EnrichmentPending
+		record.nextEnrichmentAt = *retryAt
+	}
+	record.enrichmentError = reason
+	if record.enrichmentStatus != status {
+		record.version++
+	}
+
+	return nil
+}
+
+// RequestEnrichment ставит песню в очередь на дополнение заново со сброшенным счетчиком
+// попыток. Политика выбирается так же, как в postgres-реализации
+func (m EnrichmentMemory) RequestEnrichment(ctx context.Context, id int, policy string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[id]
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена", id)
+	}
+
+	switch {
+	case policy != "":
+		record.enrichmentPolicy = policy
+	case record.enrichmentPolicy == "" || record.enrichmentPolicy == model.EnrichNever:
+		record.enrichmentPolicy = model.EnrichMissing
+	}
+
+	record.version++
+	record.enrichmentStatus = model.EnrichmentPending
+	record.enrichmentAttempts = 0
+	record.nextEnrichmentAt = m.store.now()
+	record.enrichmentError = ""
+
+	return nil
+}
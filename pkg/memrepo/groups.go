@@ -0,0 +1,179 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type GroupsMemory struct {
+	store *Store
+}
+
+func NewGroupsMemory(store *Store) *GroupsMemory {
+	return &GroupsMemory{store: store}
+}
+
+func (m GroupsMemory) GetGroups(ctx context.Context) ([]model.Group, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	groups := make([]model.Group, 0, len(m.store.groups))
+	for _, group := range m.store.groups {
+		groups = append(groups, group)
+	}
+
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Name != groups[j].Name {
+			return groups[i].Name < groups[j].Name
+		}
+		return groups[i].ID < groups[j].ID
+	})
+
+	return groups, nil
+}
+
+func (m GroupsMemory) GetGroupById(ctx context.Context, id int) (model.Group, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	group, ok := m.store.groups[id]
+	if !ok {
+		return model.Group{}, errs.NotFound("группа с id %d не найдена", id)
+	}
+
+	return group, nil
+}
+
+func (m GroupsMemory) AddGroup(ctx context.Context, req dto.AddGroupRequest) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if err := m.ensureGroupNameFree(req.Name, 0); err != nil {
+		return 0, err
+	}
+
+	return m.store.groupIdByName(req.Name), nil
+}
+
+func (m GroupsMemory) UpdateGroup(ctx context.Context, req dto.UpdateGroupRequest) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if err := m.ensureGroupNameFree(req.Name, req.Id); err != nil {
+		return err
+	}
+
+	group, ok := m.store.groups[req.Id]
+	if !ok {
+		return errs.NotFound("группа с id %d не найдена", req.Id)
+	}
+	if group.Name == req.Name {
+		return nil
+	}
+	m.store.groups[req.Id] = model.Group{ID: req.Id, Name: req.Name}
+
+	// Название группы входит в представление песни, поэтому меняются ее версия и история
+	for _, record := range m.groupSongs([]int{req.Id}) {
+		record.version++
+		m.store.recordRevision(ctx, record)
+	}
+
+	return nil
+}
+
+func (m GroupsMemory) DeleteGroup(ctx context.Context, id int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if songsCount := len(m.groupSongs([]int{id})); songsCount > 0 {
+		return errs.Conflict("у группы с id %d есть песни (%d), удаление невозможно", id, songsCount)
+	}
+
+	if _, ok := m.store.groups[id]; !ok {
+		return errs.NotFound("группа с id %d не найдена", id)
+	}
+	delete(m.store.groups, id)
+
+	return nil
+}
+
+// MergeGroups переносит все песни исходных групп, включая песни в корзине, в целевую
+// и удаляет исходные группы. В режиме DryRun хранилище не меняется
+func (m GroupsMemory) MergeGroups(ctx context.Context, req dto.MergeGroupsRequest) (dto.MergeGroupsResult, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.groups[req.TargetId]; !ok {
+		return dto.MergeGroupsResult{}, errs.NotFound("целевая группа с id %d не найдена", req.TargetId)
+	}
+
+	var missing []int
+	for _, id := range req.SourceIds {
+		if _, ok := m.store.groups[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) > 0 {
+		return dto.MergeGroupsResult{}, errs.NotFound("исходные группы не найдены: %v", missing)
+	}
+
+	result := dto.MergeGroupsResult{
+		TargetId:  req.TargetId,
+		SourceIds: req.SourceIds,
+		DryRun:    req.DryRun,
+		Songs:     []model.Song{},
+	}
+
+	records := m.groupSongs(req.SourceIds)
+	for _, record := range records {
+		result.Songs = append(result.Songs, m.store.view(record))
+	}
+	result.MovedSongs = len(result.Songs)
+
+	if req.DryRun {
+		return result, nil
+	}
+
+	for _, record := range records {
+		record.groupId = req.TargetId
+		record.version++
+		m.store.recordRevision(ctx, record)
+	}
+	for _, id := range req.SourceIds {
+		delete(m.store.groups, id)
+	}
+
+	return result, nil
+}
+
+// groupSongs возвращает песни групп ids, включая песни в корзине, по возрастанию id.
+// Как и в postgres, группу с песнями в корзине нельзя удалить
+func (m GroupsMemory) groupSongs(ids []int) []*songRecord {
+	var records []*songRecord
+	for _, record := range sortRecords(m.store.songs, m.store.trash) {
+		for _, id := range ids {
+			if record.groupId == id {
+				records = append(records, record)
+				break
+			}
+		}
+	}
+
+	return records
+}
+
+// ensureGroupNameFree проверяет, что название не занято другой группой без учета регистра
+func (m GroupsMemory) ensureGroupNameFree(name string, exceptId int) error {
+	for _, group := range m.store.groups {
+		if strings.EqualFold(group.Name, name) && group.ID != exceptId {
+			return errs.Conflict("группа с названием %q уже существует (id %d)", name, group.ID)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,199 @@
+// Package memrepo реализует репозиторий в памяти процесса. Он повторяет поведение
+// postgres-реализации настолько, насколько это нужно тестам обработчиков и сервисов,
+// и может использоваться вместо БД там, где сервис встраивается в другие приложения
+package memrepo
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/repository"
+)
+
+// songRecord хранимая песня. Название группы берется из groups при чтении,
+// text всегда собран из verses
+type songRecord struct {
+	id          int
+	song        string
+	genre       string
+	releaseDate time.Time
+	text        string
+	link        string
+	groupId     int
+	verses      []model.Verse
+	syncedLines []model.SyncedLine
+	// translations переводы по тегу языка
+	translations map[string]model.Translation
+	// revisions ревизии по возрастанию номера
+	revisions []model.SongRevision
+
+	enrichmentStatus   string
+	enrichmentPolicy   string
+	enrichmentAttempts int
+	nextEnrichmentAt   time.Time
+	enrichmentError    string
+	enrichedFields     []string
+	enrichmentSources  model.FieldSources
+
+	// deletedAt время перемещения в корзину
+	deletedAt time.Time
+	// version растет при каждом изменении представления песни, как столбец version в postgres
+	version int
+}
+
+// Store общее хранилище песен и групп для репозиториев пакета
+type Store struct {
+	mu    sync.RWMutex
+	songs map[int]*songRecord
+	// trash песни в корзине. Они не видны через songs, как строки с deleted_at в postgres
+	trash       map[int]*songRecord
+	groups      map[int]model.Group
+	nextSongId  int
+	nextGroupId int
+	nextVerseId int
+	now         func() time.Time
+}
+
+func NewStore() *Store {
+	return &Store{
+		songs:       make(map[int]*songRecord),
+		trash:       make(map[int]*songRecord),
+		groups:      make(map[int]model.Group),
+		nextSongId:  1,
+		nextGroupId: 1,
+		nextVerseId: 1,
+		now:         time.Now,
+	}
+}
+
+// NewRepository создает репозиторий поверх нового пустого хранилища
+func NewRepository() *repository.Repository {
+	return NewRepositoryWithStore(NewStore())
+}
+
+// NewRepositoryWithStore создает репозиторий поверх store, например чтобы несколько
+// экземпляров сервиса работали с одними данными
+func NewRepositoryWithStore(store *Store) *repository.Repository {
+	return &repository.Repository{
+		Songs:        NewSongsMemory(store),
+		Groups:       NewGroupsMemory(store),
+		Verses:       NewVersesMemory(store),
+		SyncedLyrics: NewSyncedLyricsMemory(store),
+		Translations: NewTranslationsMemory(store),
+		Revisions:    NewRevisionsMemory(store),
+		Trash:        NewTrashMemory(store),
+		Enrichment:   NewEnrichmentMemory(store),
+	}
+}
+
+// view собирает песню в том виде, в котором ее отдает postgres-реализация
+func (s *Store) view(record *songRecord) model.Song {
+	song := model.Song{
+		ID:                record.id,
+		Genre:             record.genre,
+		Song:              record.song,
+		Text:              record.text,
+		Link:              record.link,
+		GroupID:           record.groupId,
+		Group:             s.groups[record.groupId].Name,
+		EnrichmentStatus:  record.enrichmentStatus,
+		EnrichedFields:    pq.StringArray(append([]string{}, record.enrichedFields...)),
+		EnrichmentSources: model.FieldSources{},
+		HasSyncedLyrics:   len(record.syncedLines) > 0,
+		Version:           record.version,
+	}
+
+	if !record.releaseDate.IsZero() {
+		song.ReleaseDate = record.releaseDate.Format(dto.DateLayout)
+	}
+	if !record.deletedAt.IsZero() {
+		deletedAt := record.deletedAt
+		song.DeletedAt = &deletedAt
+	}
+	for field, source := range record.enrichmentSources {
+		song.EnrichmentSources[field] = source
+	}
+
+	return song
+}
+
+// songFields текущие значения полей песни, которые меняет UpdateSong, в формате запроса
+func (s *Store) songFields(record *songRecord) dto.SongFields {
+	fields := dto.SongFields{
+		Song:  record.song,
+		Genre: record.genre,
+		Text:  record.text,
+		Link:  record.link,
+		Group: s.groups[record.groupId].Name,
+	}
+	if !record.releaseDate.IsZero() {
+		fields.ReleaseDate = record.releaseDate.Format(dto.ISODateLayout)
+	}
+
+	return fields
+}
+
+// groupIdByName находит группу по названию без учета регистра или создает ее, как это делает postgres-реализация
+func (s *Store) groupIdByName(name string) int {
+	for _, group := range s.groups {
+		if strings.EqualFold(group.Name, name) {
+			return group.ID
+		}
+	}
+
+	id := s.nextGroupId
+	s.nextGroupId++
+	s.groups[id] = model.Group{ID: id, Name: name}
+
+	return id
+}
+
+// sortedSongs возвращает песни не из корзины по возрастанию id
+func (s *Store) sortedSongs() []*songRecord {
+	return sortRecords(s.songs)
+}
+
+// anySong находит песню, в том числе в корзине
+func (s *Store) anySong(id int) (*songRecord, bool) {
+	if record, ok := s.songs[id]; ok {
+		return record, true
+	}
+
+	record, ok := s.trash[id]
+	return record, ok
+}
+
+// sortRecords объединяет песни из нескольких хранилищ по возрастанию id
+func sortRecords(songs ...map[int]*songRecord) []*songRecord {
+	var records []*songRecord
+	for _, m := range songs {
+		for _, record := range m {
+			records = append(records, record)
+		}
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].id < records[j].id
+	})
+
+	return records
+}
+
+// containsFold проверяет вхождение без учета регистра, как ILIKE '%value%'
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// parseDate разбирает дату в формате ISO-8601 или DD.MM.YYYY; пустая строка дает нулевую дату
+func parseDate(value string) (time.Time, error) {
+	if strings.TrimSpace(value) == "" {
+		return time.Time{}, nil
+	}
+
+	return dto.ParseDate(value)
+}
@@ -0,0 +1,68 @@
+package memrepo
+
+import (
+	"context"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type RevisionsMemory struct {
+	store *Store
+}
+
+func NewRevisionsMemory(store *Store) *RevisionsMemory {
+	return &RevisionsMemory{store: store}
+}
+
+// GetRevisions возвращает ревизии песни, начиная с последней
+func (m RevisionsMemory) GetRevisions(ctx context.Context, songId int) ([]model.SongRevision, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	revisions := make([]model.SongRevision, 0, len(record.revisions))
+	for i := len(record.revisions) - 1; i >= 0; i-- {
+		revisions = append(revisions, record.revisions[i])
+	}
+
+	return revisions, nil
+}
+
+func (m RevisionsMemory) GetRevision(ctx context.Context, songId, revision int) (model.SongRevision, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok || revision < 1 || revision > len(record.revisions) {
+		return model.SongRevision{}, errs.NotFound("ревизия %d песни %d не найдена", revision, songId)
+	}
+
+	return record.revisions[revision-1], nil
+}
+
+// recordRevision сохраняет текущее состояние песни новой ревизией с автором и причиной
+// из контекста, как это делает postgres-реализация
+func (s *Store) recordRevision(ctx context.Context, record *songRecord) {
+	change := dto.ChangeFrom(ctx)
+	song := s.view(record)
+
+	record.revisions = append(record.revisions, model.SongRevision{
+		SongID:      record.id,
+		Revision:    len(record.revisions) + 1,
+		Song:        song.Song,
+		Group:       song.Group,
+		Genre:       song.Genre,
+		ReleaseDate: song.ReleaseDate,
+		Text:        song.Text,
+		Link:        song.Link,
+		Actor:       change.Actor,
+		Reason:      change.Reason,
+		CreatedAt:   s.now(),
+	})
+}
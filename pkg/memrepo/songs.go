@@ -0,0 +1,477 @@
+package memrepo
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type SongsMemory struct {
+	store *Store
+}
+
+func NewSongsMemory(store *Store) *SongsMemory {
+	return &SongsMemory{store: store}
+}
+
+func (m SongsMemory) GetSongs(ctx context.Context, req dto.GetSongsRequest) ([]model.Song, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	var after model.Song
+	if req.After != nil {
+		var err error
+		if after, err = cursorSong(req.SortBy, req.After.Values); err != nil {
+			return nil, err
+		}
+	}
+
+	songs := []model.Song{}
+	for _, record := range m.store.sortedSongs() {
+		if !matchesFilters(record, m.store.groups[record.groupId].Name, req) {
+			continue
+		}
+
+		song := m.store.view(record)
+
+		if req.After != nil {
+			result, err := compareSongs(song, after, req.SortBy)
+			if err != nil {
+				return nil, err
+			}
+			if result <= 0 {
+				continue
+			}
+		}
+
+		songs = append(songs, song)
+	}
+
+	if err := sortSongs(songs, req.SortBy); err != nil {
+		return nil, err
+	}
+
+	// Как и в postgres-реализации, смещение учитывается только вместе с лимитом
+	if req.Limit != 0 {
+		if req.Offset >= len(songs) {
+			return []model.Song{}, nil
+		}
+		songs = songs[req.Offset:]
+
+		if req.Limit < len(songs) {
+			songs = songs[:req.Limit]
+		}
+	}
+
+	return songs, nil
+}
+
+func matchesFilters(record *songRecord, group string, req dto.GetSongsRequest) bool {
+	date := record.releaseDate
+
+	switch {
+	case req.Id != 0 && record.id != req.Id,
+		req.Genre != "" && !containsFold(record.genre, req.Genre),
+		req.Song != "" && !containsFold(record.song, req.Song),
+		req.Text != "" && !containsFold(record.text, req.Text),
+		req.Link != "" && !containsFold(record.link, req.Link),
+		req.GroupId != 0 && record.groupId != req.GroupId,
+		req.Group != "" && !containsFold(group, req.Group),
+		req.EnrichmentStatus != "" && record.enrichmentStatus != req.EnrichmentStatus:
+		return false
+	}
+
+	// Песни без даты выхода не проходят ни один фильтр по дате, как NULL в SQL
+	hasDateFilter := req.ReleaseDate != nil || req.ReleasedAfter != nil || req.ReleasedBefore != nil ||
+		req.Year != 0 || req.Decade != 0
+	if hasDateFilter && date.IsZero() {
+		return false
+	}
+
+	switch {
+	case req.ReleaseDate != nil && !date.Equal(*req.ReleaseDate),
+		req.ReleasedAfter != nil && date.Before(*req.ReleasedAfter),
+		req.ReleasedBefore != nil && date.After(*req.ReleasedBefore),
+		req.Year != 0 && date.Year() != req.Year,
+		req.Decade != 0 && (date.Year() < req.Decade || date.Year() >= req.Decade+10):
+		return false
+	}
+
+	return true
+}
+
+// sortSongs упорядочивает песни по sortBy, а без сортировки — по id
+func sortSongs(songs []model.Song, sortBy []dto.SortField) error {
+	if len(sortBy) == 0 {
+		sortBy = []dto.SortField{{Field: "id"}}
+	}
+
+	var sortErr error
+	sort.SliceStable(songs, func(i, j int) bool {
+		result, err := compareSongs(songs[i], songs[j], sortBy)
+		if err != nil {
+			sortErr = err
+		}
+		return result < 0
+	})
+
+	return sortErr
+}
+
+func (m SongsMemory) AddSong(ctx context.Context, req dto.AddSongRequest, enrichmentStatus string) (int, error) {
+	releaseDate, err := parseDate(req.ReleaseDate)
+	if err != nil {
+		return 0, err
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record := &songRecord{
+		id:                m.store.nextSongId,
+		song:              req.Song,
+		genre:             req.Genre,
+		releaseDate:       releaseDate,
+		link:              req.Link,
+		groupId:           m.store.groupIdByName(req.Group),
+		enrichmentStatus:  enrichmentStatus,
+		enrichmentPolicy:  req.Enrich,
+		enrichmentSources: model.FieldSources{},
+		version:           1,
+	}
+	if record.enrichmentPolicy == "" {
+		record.enrichmentPolicy = model.EnrichMissing
+	}
+	if enrichmentStatus == model.EnrichmentPending {
+		record.nextEnrichmentAt = m.store.now()
+	}
+
+	m.store.setText(record, req.Text)
+
+	m.store.nextSongId++
+	m.store.songs[record.id] = record
+	m.store.recordRevision(ctx, record)
+
+	return record.id, nil
+}
+
+func (m SongsMemory) UpdateSong(ctx context.Context, req dto.UpdateSongRequest) (int, error) {
+	var releaseDate time.Time
+	if req.ReleaseDate != nil {
+		var err error
+		if releaseDate, err = parseDate(*req.ReleaseDate); err != nil {
+			return 0, err
+		}
+	}
+
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[req.Id]
+	if !ok {
+		return 0, errs.NotFound("песня с id %d не найдена", req.Id)
+	}
+
+	if err := req.IfMatch.Check(record.id, record.version); err != nil {
+		return 0, err
+	}
+
+	// Запрос без изменений не увеличивает версию и не записывает ревизию
+	if !req.DropUnchanged(m.store.songFields(record)) {
+		return record.version, nil
+	}
+
+	var clientFields []string
+	if req.Song != nil {
+		record.song = *req.Song
+	}
+	if req.Genre != nil {
+		record.genre = *req.Genre
+	}
+	if req.ReleaseDate != nil {
+		record.releaseDate = releaseDate
+		clientFields = append(clientFields, model.FieldReleaseDate)
+	}
+	if req.Text != nil {
+		m.store.setText(record, *req.Text)
+		clientFields = append(clientFields, model.FieldText)
+	}
+	if req.Link != nil {
+		record.link = *req.Link
+		clientFields = append(clientFields, model.FieldLink)
+	}
+	if req.Group != nil {
+		record.groupId = m.store.groupIdByName(*req.Group)
+	}
+
+	// Поля, измененные клиентом, больше не считаются взятыми из внешнего API
+	for _, field := range clientFields {
+		record.enrichedFields = removeString(record.enrichedFields, field)
+		delete(record.enrichmentSources, field)
+	}
+
+	record.version++
+	m.store.recordRevision(ctx, record)
+
+	return record.version, nil
+}
+
+// DeleteSong перемещает песню в корзину
+func (m SongsMemory) DeleteSong(ctx context.Context, id int, ifMatch dto.IfMatch) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[id]
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена", id)
+	}
+
+	if err := ifMatch.Check(id, record.version); err != nil {
+		return err
+	}
+
+	record.deletedAt = m.store.now()
+	record.version++
+	delete(m.store.songs, id)
+	m.store.trash[id] = record
+
+	return nil
+}
+
+func (m SongsMemory) GetSongById(ctx context.Context, id int) (model.Song, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, ok := m.store.songs[id]
+	if !ok {
+		return model.Song{}, errs.NotFound("песня с id %d не найдена", id)
+	}
+
+	return m.store.view(record), nil
+}
+
+// SearchSongs упрощенный полнотекстовый поиск: все слова запроса, кроме исключенных
+// минусом, должны встречаться в названии, группе, жанре или тексте. Ранг — число совпадений
+func (m SongsMemory) SearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error) {
+	include, exclude := searchTerms(req.Query)
+
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	results := []model.SongSearchResult{}
+	for _, record := range m.store.sortedSongs() {
+		song := m.store.view(record)
+		document := strings.ToLower(strings.Join([]string{song.Song, song.Group, song.Genre, song.Text}, " "))
+
+		rank := 0
+		for _, term := range include {
+			count := strings.Count(document, term)
+			if count == 0 {
+				rank = 0
+				break
+			}
+			rank += count
+		}
+		for _, term := range exclude {
+			if strings.Contains(document, term) {
+				rank = 0
+			}
+		}
+		if rank == 0 {
+			continue
+		}
+
+		results = append(results, model.SongSearchResult{
+			Song: song,
+			Rank: float64(rank),
+			Highlights: model.SearchHighlights{
+				Song:  highlight(song.Song, include),
+				Group: highlight(song.Group, include),
+				Text:  highlight(song.Text, include),
+				Genre: highlight(song.Genre, include),
+			},
+		})
+	}
+
+	return results, sortSearchResults(results, req.SortBy)
+}
+
+// FuzzySearchSongs находит песни, в названии песни или группы которых есть слово,
+// отличающееся от запроса не более чем на треть символов
+func (m SongsMemory) FuzzySearchSongs(ctx context.Context, req dto.SearchSongsRequest) ([]model.SongSearchResult, error) {
+	query := strings.ToLower(strings.TrimSpace(req.Query))
+
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	results := []model.SongSearchResult{}
+	for _, record := range m.store.sortedSongs() {
+		song := m.store.view(record)
+
+		score := max(wordSimilarity(query, song.Song), wordSimilarity(query, song.Group))
+		if score < 0.6 {
+			continue
+		}
+
+		results = append(results, model.SongSearchResult{Song: song, Rank: score})
+	}
+
+	if err := sortSearchResults(results, req.SortBy); err != nil {
+		return nil, err
+	}
+	if req.Limit != 0 && len(results) > req.Limit {
+		results = results[:req.Limit]
+	}
+
+	return results, nil
+}
+
+func (m SongsMemory) SuggestSongs(ctx context.Context, prefix string, limit int) ([]model.Suggestion, error) {
+	prefix = strings.ToLower(prefix)
+
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	suggestions := []model.Suggestion{}
+	for _, record := range m.store.sortedSongs() {
+		if strings.HasPrefix(strings.ToLower(record.song), prefix) {
+			suggestions = append(suggestions, model.Suggestion{Type: "song", ID: record.id, Value: record.song, Score: 1})
+		}
+	}
+	for _, group := range m.store.groups {
+		if strings.HasPrefix(strings.ToLower(group.Name), prefix) {
+			suggestions = append(suggestions, model.Suggestion{Type: "group", ID: group.ID, Value: group.Name, Score: 1})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Value != suggestions[j].Value {
+			return suggestions[i].Value < suggestions[j].Value
+		}
+		return suggestions[i].Type > suggestions[j].Type
+	})
+
+	if len(suggestions) > limit {
+		suggestions = suggestions[:limit]
+	}
+
+	return suggestions, nil
+}
+
+// searchTerms разбирает запрос на искомые слова и слова, исключенные минусом
+func searchTerms(query string) (include, exclude []string) {
+	for _, term := range strings.Fields(strings.ToLower(query)) {
+		term = strings.Trim(term, `"`)
+		switch {
+		case term == "" || term == "or":
+		case strings.HasPrefix(term, "-"):
+			exclude = append(exclude, strings.TrimPrefix(term, "-"))
+		default:
+			include = append(include, term)
+		}
+	}
+
+	return include, exclude
+}
+
+// highlight обрамляет найденные слова тегами <mark></mark>; если совпадений нет, возвращает nil
+func highlight(value string, terms []string) *string {
+	if value == "" || len(terms) == 0 {
+		return nil
+	}
+
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = regexp.QuoteMeta(term)
+	}
+
+	re := regexp.MustCompile("(?i)" + strings.Join(quoted, "|"))
+	if !re.MatchString(value) {
+		return nil
+	}
+
+	highlighted := re.ReplaceAllString(value, "<mark>$0</mark>")
+	return &highlighted
+}
+
+// sortSearchResults упорядочивает результаты поиска по sortBy, затем по релевантности и id
+func sortSearchResults(results []model.SongSearchResult, sortBy []dto.SortField) error {
+	var sortErr error
+	sort.SliceStable(results, func(i, j int) bool {
+		result, err := compareSongs(results[i].Song, results[j].Song, sortBy)
+		if err != nil {
+			sortErr = err
+		}
+		if result != 0 {
+			return result < 0
+		}
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].ID < results[j].ID
+	})
+
+	return sortErr
+}
+
+// wordSimilarity сходство запроса с самым похожим словом или фразой value от 0 до 1
+func wordSimilarity(query, value string) float64 {
+	value = strings.ToLower(value)
+	if query == "" || value == "" {
+		return 0
+	}
+	if strings.Contains(value, query) {
+		return 1
+	}
+
+	best := similarity(query, value)
+	for _, word := range strings.Fields(value) {
+		best = max(best, similarity(query, word))
+	}
+
+	return best
+}
+
+// similarity 1 минус расстояние Левенштейна, отнесенное к длине большей строки
+func similarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) == 0 && len(rb) == 0 {
+		return 1
+	}
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return 1 - float64(previous[len(rb)])/float64(max(len(ra), len(rb)))
+}
+
+func removeString(values []string, value string) []string {
+	result := values[:0]
+	for _, v := range values {
+		if v != value {
+			result = append(result, v)
+		}
+	}
+	return result
+}
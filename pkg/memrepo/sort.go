@@ -0,0 +1,107 @@
+package memrepo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+)
+
+// compareField сравнивает песни по полю сортировки; пустые значения меньше любых других,
+// как COALESCE в postgres-реализации
+func compareField(a, b model.Song, field string) (int, error) {
+	switch field {
+	case "id":
+		return compareInts(a.ID, b.ID), nil
+	case "song":
+		return compareStrings(a.Song, b.Song), nil
+	case "group":
+		return compareStrings(a.Group, b.Group), nil
+	case "genre":
+		return compareStrings(a.Genre, b.Genre), nil
+	case "releaseDate":
+		return compareDates(a.ReleaseDate, b.ReleaseDate), nil
+	default:
+		return 0, fmt.Errorf("неизвестное поле сортировки: %s", field)
+	}
+}
+
+// compareSongs сравнивает песни по списку полей с учетом направлений
+func compareSongs(a, b model.Song, sortBy []dto.SortField) (int, error) {
+	for _, field := range sortBy {
+		result, err := compareField(a, b, field.Field)
+		if err != nil {
+			return 0, err
+		}
+		if field.Desc {
+			result = -result
+		}
+		if result != 0 {
+			return result, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// cursorSong восстанавливает ключи сортировки песни, после которой начинается страница
+func cursorSong(sortBy []dto.SortField, values []string) (model.Song, error) {
+	if len(values) != len(sortBy) {
+		return model.Song{}, fmt.Errorf("курсор не соответствует сортировке")
+	}
+
+	var song model.Song
+	for i, field := range sortBy {
+		switch field.Field {
+		case "id":
+			id, err := strconv.Atoi(values[i])
+			if err != nil {
+				return model.Song{}, fmt.Errorf("некорректный id в курсоре: %w", err)
+			}
+			song.ID = id
+		case "song":
+			song.Song = values[i]
+		case "group":
+			song.Group = values[i]
+		case "genre":
+			song.Genre = values[i]
+		case "releaseDate":
+			song.ReleaseDate = values[i]
+		default:
+			return model.Song{}, fmt.Errorf("неизвестное поле сортировки: %s", field.Field)
+		}
+	}
+
+	return song, nil
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func compareStrings(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// compareDates сравнивает даты в формате DD.MM.YYYY
+func compareDates(a, b string) int {
+	parse := func(value string) time.Time {
+		date, err := time.Parse(dto.DateLayout, value)
+		if err != nil {
+			return time.Time{}
+		}
+		return date
+	}
+
+	return parse(a).Compare(parse(b))
+}
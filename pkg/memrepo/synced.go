@@ -0,0 +1,58 @@
+package memrepo
+
+import (
+	"context"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type SyncedLyricsMemory struct {
+	store *Store
+}
+
+func NewSyncedLyricsMemory(store *Store) *SyncedLyricsMemory {
+	return &SyncedLyricsMemory{store: store}
+}
+
+func (m SyncedLyricsMemory) GetSyncedLines(ctx context.Context, songId int) ([]model.SyncedLine, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	return append([]model.SyncedLine{}, record.syncedLines...), nil
+}
+
+func (m SyncedLyricsMemory) SetSyncedLines(ctx context.Context, songId int, lines []model.SyncedLine) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	record.syncedLines = append([]model.SyncedLine{}, lines...)
+	record.version++
+
+	return nil
+}
+
+func (m SyncedLyricsMemory) DeleteSyncedLines(ctx context.Context, songId int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok || len(record.syncedLines) == 0 {
+		return errs.NotFound("синхронизированный текст песни %d не найден", songId)
+	}
+
+	record.syncedLines = nil
+	record.version++
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type TranslationsMemory struct {
+	store *Store
+}
+
+func NewTranslationsMemory(store *Store) *TranslationsMemory {
+	return &TranslationsMemory{store: store}
+}
+
+func (m TranslationsMemory) GetTranslations(ctx context.Context, songId int) ([]model.Translation, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	translations := []model.Translation{}
+	for _, translation := range record.translations {
+		translations = append(translations, translation)
+	}
+	sort.Slice(translations, func(i, j int) bool {
+		return translations[i].Lang < translations[j].Lang
+	})
+
+	return translations, nil
+}
+
+func (m TranslationsMemory) GetTranslation(ctx context.Context, songId int, lang string) (model.Translation, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	translation, ok := m.store.songs[songId].lookupTranslation(lang)
+	if !ok {
+		return model.Translation{}, errs.NotFound("перевод песни %d на язык %s не найден", songId, lang)
+	}
+
+	return translation, nil
+}
+
+func (m TranslationsMemory) SaveTranslation(ctx context.Context, songId int, lang, text string) (model.Translation, bool, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok {
+		return model.Translation{}, false, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	if record.translations == nil {
+		record.translations = make(map[string]model.Translation)
+	}
+	_, exists := record.translations[lang]
+
+	translation := model.Translation{SongID: songId, Lang: lang, Text: text, UpdatedAt: m.store.now()}
+	record.translations[lang] = translation
+
+	return translation, !exists, nil
+}
+
+func (m TranslationsMemory) DeleteTranslation(ctx context.Context, songId int, lang string) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	if _, ok := m.store.songs[songId].lookupTranslation(lang); !ok {
+		return errs.NotFound("перевод песни %d на язык %s не найден", songId, lang)
+	}
+	delete(m.store.songs[songId].translations, lang)
+
+	return nil
+}
+
+// lookupTranslation ищет перевод; у отсутствующей песни (nil) переводов нет
+func (r *songRecord) lookupTranslation(lang string) (model.Translation, bool) {
+	if r == nil {
+		return model.Translation{}, false
+	}
+
+	translation, ok := r.translations[lang]
+	return translation, ok
+}
@@ -0,0 +1,69 @@
+package memrepo
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type TrashMemory struct {
+	store *Store
+}
+
+func NewTrashMemory(store *Store) *TrashMemory {
+	return &TrashMemory{store: store}
+}
+
+// GetDeletedSongs возвращает песни в корзине, начиная с удаленных последними
+func (m TrashMemory) GetDeletedSongs(ctx context.Context) ([]model.Song, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	records := sortRecords(m.store.trash)
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].deletedAt.After(records[j].deletedAt)
+	})
+
+	songs := make([]model.Song, 0, len(records))
+	for _, record := range records {
+		songs = append(songs, m.store.view(record))
+	}
+
+	return songs, nil
+}
+
+func (m TrashMemory) RestoreSong(ctx context.Context, id int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.trash[id]
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена в корзине", id)
+	}
+
+	record.deletedAt = time.Time{}
+	record.version++
+	delete(m.store.trash, id)
+	m.store.songs[id] = record
+
+	return nil
+}
+
+// PurgeDeletedSongs окончательно удаляет песни, перемещенные в корзину раньше deletedBefore
+func (m TrashMemory) PurgeDeletedSongs(ctx context.Context, deletedBefore time.Time) (int, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	purged := 0
+	for id, record := range m.store.trash {
+		if record.deletedAt.Before(deletedBefore) {
+			delete(m.store.trash, id)
+			purged++
+		}
+	}
+
+	return purged, nil
+}
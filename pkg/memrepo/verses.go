@@ -0,0 +1,213 @@
+package memrepo
+
+import (
+	"context"
+	"slices"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/dto"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/model"
+	"github.com/pelicanch1k/EffectiveMobileTestTask/internal/service/errs"
+)
+
+type VersesMemory struct {
+	store *Store
+}
+
+func NewVersesMemory(store *Store) *VersesMemory {
+	return &VersesMemory{store: store}
+}
+
+func (m VersesMemory) GetSongVerses(ctx context.Context, songId int) ([]model.Verse, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, ok := m.store.songs[songId]
+	if !ok {
+		return nil, errs.NotFound("песня с id %d не найдена", songId)
+	}
+
+	return append([]model.Verse{}, record.verses...), nil
+}
+
+func (m VersesMemory) GetVerse(ctx context.Context, songId, verseId int) (model.Verse, error) {
+	m.store.mu.RLock()
+	defer m.store.mu.RUnlock()
+
+	record, index, err := m.store.findVerse(songId, verseId)
+	if err != nil {
+		return model.Verse{}, err
+	}
+
+	return record.verses[index], nil
+}
+
+// AddVerse вставляет куплет на позицию req.Position, сдвигая последующие, или в конец песни
+func (m VersesMemory) AddVerse(ctx context.Context, req dto.AddVerseRequest) (model.Verse, error) {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[req.SongId]
+	if !ok {
+		return model.Verse{}, errs.NotFound("песня с id %d не найдена", req.SongId)
+	}
+
+	count := len(record.verses)
+	position := req.Position
+	if position == 0 {
+		position = count + 1
+	}
+	if position > count+1 {
+		return model.Verse{}, errs.Validation("позиция куплета должна быть от 1 до %d, получено %d", count+1, position)
+	}
+
+	verse := model.Verse{ID: m.store.nextVerseId, SongID: record.id, Type: req.Type, Text: req.Text}
+	if verse.Type == "" {
+		verse.Type = model.VerseTypeVerse
+	}
+	m.store.nextVerseId++
+
+	record.verses = slices.Insert(record.verses, position-1, verse)
+	record.renumberVerses()
+	record.refreshText()
+	m.store.recordRevision(ctx, record)
+
+	return record.verses[position-1], nil
+}
+
+func (m VersesMemory) UpdateVerse(ctx context.Context, req dto.UpdateVerseRequest) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, index, err := m.store.findVerse(req.SongId, req.VerseId)
+	if err != nil {
+		return err
+	}
+
+	if req.Type != nil {
+		record.verses[index].Type = *req.Type
+	}
+	if req.Text == nil {
+		// Тип куплета не входит в ревизию, как и в postgres-реализации
+		record.version++
+		return nil
+	}
+
+	record.verses[index].Text = *req.Text
+	record.refreshText()
+	m.store.recordRevision(ctx, record)
+
+	return nil
+}
+
+// DeleteVerse удаляет куплет и сдвигает последующие, чтобы позиции шли подряд
+func (m VersesMemory) DeleteVerse(ctx context.Context, songId, verseId int) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, index, err := m.store.findVerse(songId, verseId)
+	if err != nil {
+		return err
+	}
+
+	record.verses = slices.Delete(record.verses, index, index+1)
+	record.renumberVerses()
+	record.refreshText()
+	m.store.recordRevision(ctx, record)
+
+	return nil
+}
+
+// ReorderVerses расставляет куплеты в порядке req.VerseIds, который должен
+// содержать каждый куплет песни ровно один раз
+func (m VersesMemory) ReorderVerses(ctx context.Context, req dto.ReorderVersesRequest) error {
+	m.store.mu.Lock()
+	defer m.store.mu.Unlock()
+
+	record, ok := m.store.songs[req.SongId]
+	if !ok {
+		return errs.NotFound("песня с id %d не найдена", req.SongId)
+	}
+
+	if len(req.VerseIds) != len(record.verses) {
+		return errs.Validation("нужно указать все куплеты песни (%d), указано %d", len(record.verses), len(req.VerseIds))
+	}
+
+	byId := make(map[int]model.Verse, len(record.verses))
+	for _, verse := range record.verses {
+		byId[verse.ID] = verse
+	}
+
+	verses := make([]model.Verse, 0, len(req.VerseIds))
+	for _, id := range req.VerseIds {
+		verse, ok := byId[id]
+		if !ok {
+			return errs.Validation("куплет %d не принадлежит песне или указан дважды", id)
+		}
+		delete(byId, id)
+		verses = append(verses, verse)
+	}
+
+	record.verses = verses
+	record.renumberVerses()
+	record.refreshText()
+	m.store.recordRevision(ctx, record)
+
+	return nil
+}
+
+func (s *Store) findVerse(songId, verseId int) (*songRecord, int, error) {
+	record, ok := s.songs[songId]
+	if !ok {
+		return nil, 0, errs.NotFound("куплет %d песни %d не найден", verseId, songId)
+	}
+
+	for i, verse := range record.verses {
+		if verse.ID == verseId {
+			return record, i, nil
+		}
+	}
+
+	return nil, 0, errs.NotFound("куплет %d песни %d не найден", verseId, songId)
+}
+
+// setText заменяет куплеты песни куплетами text, как это делает postgres-реализация
+// при записи текста целиком
+func (s *Store) setText(record *songRecord, text string) {
+	record.verses = nil
+	for _, verseText := range dto.SplitVerses(text) {
+		record.verses = append(record.verses, model.Verse{
+			ID:     s.nextVerseId,
+			SongID: record.id,
+			Type:   model.VerseTypeVerse,
+			Text:   verseText,
+		})
+		s.nextVerseId++
+	}
+
+	record.renumberVerses()
+	record.text = record.joinVerses()
+}
+
+func (r *songRecord) renumberVerses() {
+	for i := range r.verses {
+		r.verses[i].Position = i + 1
+	}
+}
+
+func (r *songRecord) joinVerses() string {
+	texts := make([]string, len(r.verses))
+	for i, verse := range r.verses {
+		texts[i] = verse.Text
+	}
+
+	return dto.JoinVerses(texts)
+}
+
+// refreshText пересобирает текст из куплетов после их правки. Такой текст
+// больше не считается взятым из внешнего API
+func (r *songRecord) refreshText() {
+	r.text = r.joinVerses()
+	r.enrichedFields = removeString(r.enrichedFields, model.FieldText)
+	delete(r.enrichmentSources, model.FieldText)
+	r.version++
+}
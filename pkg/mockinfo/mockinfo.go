@@ -0,0 +1,166 @@
+// Package mockinfo реализует контракт внешнего сервиса информации о песнях
+// (GET /info?group=...&song=...) поверх набора фикстур. Используется для локальной
+// разработки (cmd/mockinfo) и в тестах через mockinfotest.NewServer. Умеет имитировать
+// задержки, ошибки сервера и некорректный JSON
+package mockinfo
+
+import (
+	"encoding/json"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Fixture ответ сервиса для пары группа+песня
+type Fixture struct {
+	Group       string `json:"group"`
+	Song        string `json:"song"`
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+// Faults имитируемые сбои. Вероятности задаются от 0 до 1
+type Faults struct {
+	// Latency задержка перед каждым ответом
+	Latency time.Duration
+	// ErrorRate доля запросов, на которые сервер отвечает ErrorStatus
+	ErrorRate float64
+	// ErrorStatus код ответа при имитации ошибки, по умолчанию 500
+	ErrorStatus int
+	// MalformedRate доля успешных ответов с обрезанным JSON
+	MalformedRate float64
+}
+
+// SongDetail тело успешного ответа
+type SongDetail struct {
+	ReleaseDate string `json:"releaseDate"`
+	Text        string `json:"text"`
+	Link        string `json:"link"`
+}
+
+type Server struct {
+	mu       sync.RWMutex
+	fixtures map[string]Fixture
+	faults   Faults
+
+	requests atomic.Int64
+}
+
+func New(fixtures []Fixture) *Server {
+	s := &Server{fixtures: make(map[string]Fixture, len(fixtures))}
+	for _, fixture := range fixtures {
+		s.AddFixture(fixture)
+	}
+	return s
+}
+
+// LoadFixtures читает фикстуры из JSON-файла с массивом объектов Fixture
+func LoadFixtures(path string) ([]Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixtures []Fixture
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, err
+	}
+
+	return fixtures, nil
+}
+
+func (s *Server) AddFixture(fixture Fixture) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fixtures[fixtureKey(fixture.Group, fixture.Song)] = fixture
+}
+
+func (s *Server) SetFaults(faults Faults) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = faults
+}
+
+// Requests число запросов к /info с момента запуска
+func (s *Server) Requests() int64 {
+	return s.requests.Load()
+}
+
+// ServeHTTP обслуживает /info и /api/info, чтобы подходил и адрес с префиксом /api по умолчанию
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/info" && r.URL.Path != "/api/info" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	s.requests.Add(1)
+
+	s.mu.RLock()
+	faults := s.faults
+	s.mu.RUnlock()
+
+	if faults.Latency > 0 {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(faults.Latency):
+		}
+	}
+
+	if faults.ErrorRate > 0 && rand.Float64() < faults.ErrorRate {
+		status := faults.ErrorStatus
+		if status == 0 {
+			status = http.StatusInternalServerError
+		}
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+
+	group, song := r.URL.Query().Get("group"), r.URL.Query().Get("song")
+	if group == "" || song == "" {
+		http.Error(w, "group and song are required", http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	fixture, ok := s.fixtures[fixtureKey(group, song)]
+	s.mu.RUnlock()
+
+	if !ok {
+		http.Error(w, "song not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if faults.MalformedRate > 0 && rand.Float64() < faults.MalformedRate {
+		w.Write([]byte(`{"releaseDate": "` + fixture.ReleaseDate))
+		return
+	}
+
+	json.NewEncoder(w).Encode(SongDetail{
+		ReleaseDate: fixture.ReleaseDate,
+		Text:        fixture.Text,
+		Link:        fixture.Link,
+	})
+}
+
+// fixtureKey ключ фикстуры без учета регистра и лишних пробелов
+func fixtureKey(group, song string) string {
+	normalize := func(value string) string {
+		return strings.ToLower(strings.Join(strings.Fields(value), " "))
+	}
+	return normalize(group) + "\x00" + normalize(song)
+}
@@ -0,0 +1,21 @@
+// Package mockinfotest поднимает мок сервиса информации о песнях как httptest-сервер в тестах
+package mockinfotest
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/pelicanch1k/EffectiveMobileTestTask/pkg/mockinfo"
+)
+
+// NewServer запускает httptest-сервер с фикстурами и останавливает его по завершении теста.
+// Возвращает сервер для управления сбоями и базовый URL для URL_ADD_SONG
+func NewServer(tb testing.TB, fixtures ...mockinfo.Fixture) (*mockinfo.Server, string) {
+	tb.Helper()
+
+	s := mockinfo.New(fixtures)
+	srv := httptest.NewServer(s)
+	tb.Cleanup(srv.Close)
+
+	return s, srv.URL + "/api"
+}